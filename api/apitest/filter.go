@@ -0,0 +1,168 @@
+package apitest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// queryTasks resolves Query.tasks: the user's tasks, filtered, sorted & paged by the input
+func (s *Server) queryTasks(u *user, input map[string]any) ([]*task, error) {
+	statuses := map[string]bool{}
+	if list, ok := input["status"].([]any); ok {
+		for _, status := range list {
+			statuses[fmt.Sprint(status)] = true
+		}
+	} else if status, ok := input["status"].(string); ok {
+		statuses[status] = true
+	}
+
+	var filters []func(t *task) bool
+	if search := argString(input, "search"); search != nil {
+		lower := strings.ToLower(*search)
+		filters = append(filters, func(t *task) bool {
+			return strings.Contains(strings.ToLower(t.name), lower) || strings.Contains(strings.ToLower(t.description), lower)
+		})
+	}
+	if expr := argString(input, "regex"); expr != nil {
+		re, err := regexp.Compile(*expr)
+		if err != nil {
+			return nil, errorWithCode(codeBadUserInput, "invalid regex: "+err.Error())
+		}
+		filters = append(filters, func(t *task) bool {
+			return re.MatchString(t.name) || re.MatchString(t.description)
+		})
+	}
+
+	for _, r := range []struct {
+		name  string
+		field func(t *task) *time.Time
+	}{
+		{"due", func(t *task) *time.Time { return t.dueDate }},
+		{"created", func(t *task) *time.Time { return &t.createdAt }},
+		{"updated", func(t *task) *time.Time { return &t.updatedAt }},
+	} {
+		filter, err := rangeFilter(input, r.name, r.field)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			filters = append(filters, filter)
+		}
+	}
+	if overdue, _ := input["overdue"].(bool); overdue {
+		now := s.Now()
+		filters = append(filters, func(t *task) bool {
+			return t.status != statusDone && t.dueDate != nil && t.dueDate.Before(now)
+		})
+	}
+
+	if input["parentID"] != nil {
+		parentID, err := argID(input, "parentID")
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, func(t *task) bool { return t.parent != nil && t.parent.id == parentID })
+	}
+
+	after, err := argTime(input, "eventsAfter")
+	if err != nil {
+		return nil, err
+	}
+	before, err := argTime(input, "eventsBefore")
+	if err != nil {
+		return nil, err
+	}
+	if after != nil || before != nil {
+		withEvents := map[*task]bool{}
+		for _, e := range s.events {
+			if !e.deleted && inRange(e.startAt, after, before) {
+				withEvents[e.task] = true
+			}
+		}
+		filters = append(filters, func(t *task) bool { return withEvents[t] })
+	}
+
+	var tasks []*task
+next:
+	for _, t := range s.tasks {
+		if t.user != u || t.deleted || len(statuses) > 0 && !statuses[t.status] {
+			continue
+		}
+		for _, filter := range filters {
+			if !filter(t) {
+				continue next
+			}
+		}
+		tasks = append(tasks, t)
+	}
+
+	sortTasks(tasks, argString(input, "sort"), input["reverse"] == true)
+	return page(tasks, argInt(input, "limit"), argInt(input, "offset")), nil
+}
+
+// rangeFilter filters by the <name>After & <name>Before times in the input, nil if neither is given
+func rangeFilter(input map[string]any, name string, field func(t *task) *time.Time) (func(t *task) bool, error) {
+	after, err := argTime(input, name+"After")
+	if err != nil {
+		return nil, err
+	}
+	before, err := argTime(input, name+"Before")
+	if err != nil {
+		return nil, err
+	}
+	if after == nil && before == nil {
+		return nil, nil
+	}
+
+	return func(t *task) bool {
+		v := field(t)
+		return v != nil && inRange(*v, after, before)
+	}, nil
+}
+
+func inRange(t time.Time, after, before *time.Time) bool {
+	return (after == nil || t.After(*after)) && (before == nil || t.Before(*before))
+}
+
+// sortTasks sorts by the field, then ID. Tasks without due date go last when sorting by it
+func sortTasks(tasks []*task, by *string, reverse bool) {
+	field := ""
+	if by != nil {
+		field = *by
+	}
+
+	less := func(a, b *task) bool {
+		switch field {
+		case "DUE_DATE":
+			if (a.dueDate == nil) != (b.dueDate == nil) {
+				return b.dueDate == nil
+			}
+			if a.dueDate != nil && !a.dueDate.Equal(*b.dueDate) {
+				return a.dueDate.Before(*b.dueDate)
+			}
+		case "CREATED_AT":
+			if !a.createdAt.Equal(b.createdAt) {
+				return a.createdAt.Before(b.createdAt)
+			}
+		case "UPDATED_AT":
+			if !a.updatedAt.Equal(b.updatedAt) {
+				return a.updatedAt.Before(b.updatedAt)
+			}
+		case "NAME":
+			if nameA, nameB := strings.ToLower(a.name), strings.ToLower(b.name); nameA != nameB {
+				return nameA < nameB
+			}
+		}
+		return a.id < b.id
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if reverse {
+			return less(tasks[j], tasks[i])
+		}
+		return less(tasks[i], tasks[j])
+	})
+}
@@ -0,0 +1,294 @@
+package apitest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/todopeer/cli/util/gql"
+)
+
+// introspectionSDL declares the introspection types, served by __schema & __type
+const introspectionSDL = `
+enum __TypeKind {
+  SCALAR
+  OBJECT
+  INTERFACE
+  UNION
+  ENUM
+  INPUT_OBJECT
+  LIST
+  NON_NULL
+}
+
+type __Schema {
+  description: String
+  types: [__Type!]!
+  queryType: __Type!
+  mutationType: __Type
+  subscriptionType: __Type
+  directives: [__Directive!]!
+}
+
+type __Type {
+  kind: __TypeKind!
+  name: String
+  description: String
+  fields(includeDeprecated: Boolean = false): [__Field!]
+  interfaces: [__Type!]
+  possibleTypes: [__Type!]
+  enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+  inputFields: [__InputValue!]
+  ofType: __Type
+  specifiedByURL: String
+}
+
+type __Field {
+  name: String!
+  description: String
+  args: [__InputValue!]!
+  type: __Type!
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __InputValue {
+  name: String!
+  description: String
+  type: __Type!
+  defaultValue: String
+}
+
+type __EnumValue {
+  name: String!
+  description: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __Directive {
+  name: String!
+  description: String
+  locations: [String!]!
+  args: [__InputValue!]!
+  isRepeatable: Boolean!
+}
+`
+
+// newSchema parses the SDL, with the introspection types & root fields added
+func newSchema(sdl string) (*gql.Schema, error) {
+	schema, err := gql.ParseSchema(sdl + introspectionSDL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := schema.Types[schema.QueryType]
+	if query == nil {
+		return nil, fmt.Errorf("no query type %s", schema.QueryType)
+	}
+	query.Fields = append(query.Fields,
+		&gql.FieldDef{Name: "__schema", Type: &gql.TypeRef{Name: "__Schema", NonNull: true}},
+		&gql.FieldDef{
+			Name: "__type",
+			Args: []*gql.InputValueDef{{Name: "name", Type: &gql.TypeRef{Name: "String", NonNull: true}}},
+			Type: &gql.TypeRef{Name: "__Type"},
+		},
+	)
+	return schema, nil
+}
+
+// introspect resolves __schema & __type. found is false for other fields
+func (s *Server) introspect(name string, args map[string]any) (value any, found bool) {
+	switch name {
+	case "__schema":
+		return &object{typeName: "__Schema", field: func(name string, args map[string]any) (any, error) {
+			switch name {
+			case "types":
+				names := make([]string, 0, len(s.schema.Types))
+				for name := range s.schema.Types {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				res := make([]*object, len(names))
+				for i, name := range names {
+					res[i] = s.typeObject(s.schema.Types[name])
+				}
+				return res, nil
+			case "queryType":
+				return s.typeObject(s.schema.Types[s.schema.QueryType]), nil
+			case "mutationType":
+				return s.typeObject(s.schema.Types[s.schema.MutationType]), nil
+			case "directives":
+				return []*object{}, nil
+			}
+			return nil, nil
+		}}, true
+	case "__type":
+		typeName, _ := args["name"].(string)
+		if t := s.schema.Types[typeName]; t != nil {
+			return s.typeObject(t), true
+		}
+		return (*object)(nil), true
+	}
+	return nil, false
+}
+
+func (s *Server) typeObject(t *gql.TypeDef) *object {
+	if t == nil {
+		return nil
+	}
+
+	return &object{typeName: "__Type", field: func(name string, args map[string]any) (any, error) {
+		includeDeprecated, _ := args["includeDeprecated"].(bool)
+
+		switch name {
+		case "kind":
+			return string(t.Kind), nil
+		case "name":
+			return t.Name, nil
+		case "description":
+			return nullable(t.Description), nil
+		case "fields":
+			if t.Kind != gql.KindObject && t.Kind != gql.KindInterface {
+				return nil, nil
+			}
+			res := []*object{}
+			for _, f := range t.Fields {
+				// __schema & __type are implicit
+				if strings.HasPrefix(f.Name, "__") {
+					continue
+				}
+				if !f.Deprecated || includeDeprecated {
+					res = append(res, s.fieldObject(f))
+				}
+			}
+			return res, nil
+		case "interfaces", "possibleTypes":
+			names := t.Interfaces
+			if name == "possibleTypes" {
+				names = t.PossibleTypes
+			}
+			res := []*object{}
+			for _, n := range names {
+				res = append(res, s.typeObject(s.schema.Types[n]))
+			}
+			return res, nil
+		case "enumValues":
+			if t.Kind != gql.KindEnum {
+				return nil, nil
+			}
+			res := []*object{}
+			for _, v := range t.EnumValues {
+				if !v.Deprecated || includeDeprecated {
+					res = append(res, enumValueObject(v))
+				}
+			}
+			return res, nil
+		case "inputFields":
+			if t.Kind != gql.KindInputObject {
+				return nil, nil
+			}
+			res := []*object{}
+			for _, v := range t.InputFields {
+				res = append(res, s.inputValueObject(v))
+			}
+			return res, nil
+		}
+		return nil, nil
+	}}
+}
+
+// typeRefObject resolves a type reference, wrapping the named type with LIST & NON_NULL
+func (s *Server) typeRefObject(ref *gql.TypeRef) *object {
+	if ref.NonNull {
+		inner := *ref
+		inner.NonNull = false
+		return wrapperObject("NON_NULL", s.typeRefObject(&inner))
+	}
+	if ref.Elem != nil {
+		return wrapperObject("LIST", s.typeRefObject(ref.Elem))
+	}
+	if t := s.schema.Types[ref.Name]; t != nil {
+		return s.typeObject(t)
+	}
+	return s.typeObject(&gql.TypeDef{Kind: gql.KindScalar, Name: ref.Name})
+}
+
+func wrapperObject(kind string, ofType *object) *object {
+	return &object{typeName: "__Type", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "kind":
+			return kind, nil
+		case "ofType":
+			return ofType, nil
+		}
+		return nil, nil
+	}}
+}
+
+func (s *Server) fieldObject(f *gql.FieldDef) *object {
+	return &object{typeName: "__Field", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "name":
+			return f.Name, nil
+		case "description":
+			return nullable(f.Description), nil
+		case "args":
+			res := []*object{}
+			for _, arg := range f.Args {
+				res = append(res, s.inputValueObject(arg))
+			}
+			return res, nil
+		case "type":
+			return s.typeRefObject(f.Type), nil
+		case "isDeprecated":
+			return f.Deprecated, nil
+		case "deprecationReason":
+			return nullable(f.DeprecationReason), nil
+		}
+		return nil, nil
+	}}
+}
+
+func (s *Server) inputValueObject(v *gql.InputValueDef) *object {
+	return &object{typeName: "__InputValue", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "name":
+			return v.Name, nil
+		case "description":
+			return nullable(v.Description), nil
+		case "type":
+			return s.typeRefObject(v.Type), nil
+		case "defaultValue":
+			if v.Default == nil {
+				return nil, nil
+			}
+			return v.Default.Raw, nil
+		}
+		return nil, nil
+	}}
+}
+
+func enumValueObject(v *gql.EnumValueDef) *object {
+	return &object{typeName: "__EnumValue", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "name":
+			return v.Name, nil
+		case "description":
+			return nullable(v.Description), nil
+		case "isDeprecated":
+			return v.Deprecated, nil
+		case "deprecationReason":
+			return nullable(v.DeprecationReason), nil
+		}
+		return nil, nil
+	}}
+}
+
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,606 @@
+package apitest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type user struct {
+	id       int
+	email    string
+	password string
+	name     string
+
+	// the running task & event, nil if nothing is running
+	runningTask  *task
+	runningEvent *event
+}
+
+type task struct {
+	id          int64
+	user        *user
+	name        string
+	description string
+	status      string
+	createdAt   time.Time
+	updatedAt   time.Time
+	dueDate     *time.Time
+	parent      *task
+	deleted     bool
+}
+
+type event struct {
+	id          int64
+	user        *user
+	task        *task
+	startAt     time.Time
+	endAt       *time.Time
+	description *string
+	deleted     bool
+}
+
+const (
+	statusNotStarted = "NOT_STARTED"
+	statusDoing      = "DOING"
+	statusDone       = "DONE"
+	statusPaused     = "PAUSED"
+)
+
+var errAccessDenied = errorWithCode(codeUnauthenticated, "access denied")
+
+func errNotFound(kind string, id int64) error {
+	return errorWithCode(codeNotFound, fmt.Sprintf("%s(id=%d) not found", kind, id))
+}
+
+func (s *Server) queryRoot(u *user) *object {
+	return &object{typeName: "Query", field: func(name string, args map[string]any) (any, error) {
+		if value, found := s.introspect(name, args); found {
+			return value, nil
+		}
+		if u == nil {
+			return nil, errAccessDenied
+		}
+
+		switch name {
+		case "me":
+			return s.userObject(u), nil
+		case "tasks":
+			tasks, err := s.queryTasks(u, argObject(args, "input"))
+			if err != nil {
+				return nil, err
+			}
+
+			res := make([]*object, len(tasks))
+			for i, t := range tasks {
+				res[i] = s.taskObject(t)
+			}
+			return res, nil
+		case "task":
+			t, err := s.findTask(u, args)
+			if err != nil {
+				return nil, err
+			}
+			return s.taskObject(t), nil
+		case "events":
+			since, err := argTime(args, "since")
+			if err != nil || since == nil {
+				return nil, errorWithCode(codeBadUserInput, "since must be a valid time")
+			}
+			days, _ := args["days"].(float64)
+			until := since.AddDate(0, 0, int(days))
+
+			var events []*event
+			for _, e := range s.events {
+				if e.user == u && !e.deleted && !e.startAt.Before(*since) && e.startAt.Before(until) {
+					events = append(events, e)
+				}
+			}
+			events = page(latestFirst(events), argInt(args, "limit"), argInt(args, "offset"))
+			return s.eventsResult(events), nil
+		case "event":
+			e, err := s.findEvent(u, args)
+			if err != nil {
+				return nil, err
+			}
+			return s.eventObject(e), nil
+		}
+		return nil, fmt.Errorf("field %s not implemented", name)
+	}}
+}
+
+func (s *Server) mutationRoot(u *user) *object {
+	return &object{typeName: "Mutation", field: func(name string, args map[string]any) (any, error) {
+		if name == "login" {
+			return s.login(argObject(args, "input"))
+		}
+		if u == nil {
+			return nil, errAccessDenied
+		}
+
+		now := s.Now()
+		switch name {
+		case "logout":
+			for token, tu := range s.tokens {
+				if tu == u {
+					delete(s.tokens, token)
+				}
+			}
+			return true, nil
+		case "taskCreate":
+			input := argObject(args, "input")
+			t := &task{id: s.genID(), user: u, status: statusNotStarted, createdAt: now, updatedAt: now}
+			t.name, _ = input["name"].(string)
+			if desc, ok := input["description"].(string); ok {
+				t.description = desc
+			}
+			if err := t.setDueDate(input); err != nil {
+				return nil, err
+			}
+			if err := s.setParent(u, t, input); err != nil {
+				return nil, err
+			}
+			s.tasks = append(s.tasks, t)
+			return s.taskObject(t), nil
+		case "taskStart":
+			t, err := s.findTask(u, args)
+			if err != nil {
+				return nil, err
+			}
+
+			input := argObject(args, "input")
+			startAt, err := argTime(input, "startAt")
+			if err != nil {
+				return nil, err
+			}
+			if startAt == nil {
+				startAt = &now
+			}
+			e := s.startTask(u, t, *startAt, argString(input, "description"))
+			return &object{typeName: "TaskStartResult", field: func(name string, args map[string]any) (any, error) {
+				switch name {
+				case "task":
+					return s.taskObject(t), nil
+				case "event":
+					return s.eventObject(e), nil
+				}
+				return nil, fmt.Errorf("field %s not implemented", name)
+			}}, nil
+		case "taskUpdate":
+			t, err := s.findTask(u, args)
+			if err != nil {
+				return nil, err
+			}
+			if err = s.updateTask(u, t, argObject(args, "input"), now); err != nil {
+				return nil, err
+			}
+			return s.taskObject(t), nil
+		case "taskDelete":
+			t, err := s.findTask(u, args)
+			if err != nil {
+				return nil, err
+			}
+			if u.runningTask == t {
+				s.stopRunning(u, now, statusPaused)
+			}
+			t.deleted = true
+			t.updatedAt = now
+			return s.taskObject(t), nil
+		case "taskUndelete":
+			id, err := argID(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range s.tasks {
+				if t.id == id && t.user == u && t.deleted {
+					t.deleted = false
+					t.updatedAt = now
+					return s.taskObject(t), nil
+				}
+			}
+			return nil, errNotFound("deleted task", id)
+		case "eventUpdate":
+			e, err := s.findEvent(u, args)
+			if err != nil {
+				return nil, err
+			}
+			if err = s.updateEvent(u, e, argObject(args, "input")); err != nil {
+				return nil, err
+			}
+			return s.eventObject(e), nil
+		case "eventDelete":
+			e, err := s.findEvent(u, args)
+			if err != nil {
+				return nil, err
+			}
+			if u.runningEvent == e {
+				u.runningTask.status = statusPaused
+				u.runningTask, u.runningEvent = nil, nil
+			}
+			e.deleted = true
+			return s.eventObject(e), nil
+		}
+		return nil, fmt.Errorf("field %s not implemented", name)
+	}}
+}
+
+func (s *Server) login(input map[string]any) (any, error) {
+	email, _ := input["email"].(string)
+	password, _ := input["password"].(string)
+
+	for _, u := range s.users {
+		if u.email == email && u.password == password {
+			token := s.newToken(u)
+			return &object{typeName: "AuthPayload", field: func(name string, args map[string]any) (any, error) {
+				switch name {
+				case "user":
+					return s.userObject(u), nil
+				case "token":
+					return token, nil
+				}
+				return nil, fmt.Errorf("field %s not implemented", name)
+			}}, nil
+		}
+	}
+	return nil, errorWithCode(codeUnauthenticated, "invalid email or password")
+}
+
+// startTask makes t the running task of the user, with a new event. The previous running task gets paused.
+// Starting the running task again keeps its event
+func (s *Server) startTask(u *user, t *task, startAt time.Time, desc *string) *event {
+	if u.runningTask == t && u.runningEvent != nil {
+		return u.runningEvent
+	}
+	if u.runningTask != nil {
+		s.stopRunning(u, startAt, statusPaused)
+	}
+
+	e := &event{id: s.genID(), user: u, task: t, startAt: startAt, description: desc}
+	s.events = append(s.events, e)
+
+	t.status = statusDoing
+	t.updatedAt = s.Now()
+	u.runningTask, u.runningEvent = t, e
+	return e
+}
+
+// stopRunning ends the running event at endAt, and sets the running task to the status
+func (s *Server) stopRunning(u *user, endAt time.Time, status string) {
+	if e := u.runningEvent; e != nil {
+		if endAt.Before(e.startAt) {
+			endAt = e.startAt
+		}
+		e.endAt = &endAt
+	}
+	if t := u.runningTask; t != nil {
+		t.status = status
+		t.updatedAt = s.Now()
+	}
+	u.runningTask, u.runningEvent = nil, nil
+}
+
+func (s *Server) updateTask(u *user, t *task, input map[string]any, now time.Time) error {
+	if name, ok := input["name"].(string); ok {
+		t.name = name
+	}
+	if desc, ok := input["description"].(string); ok {
+		t.description = desc
+	}
+	if err := t.setDueDate(input); err != nil {
+		return err
+	}
+	if err := s.setParent(u, t, input); err != nil {
+		return err
+	}
+
+	if status, ok := input["status"].(string); ok && status != t.status {
+		switch {
+		case status == statusDoing:
+			s.startTask(u, t, now, nil)
+		case u.runningTask == t:
+			s.stopRunning(u, now, status)
+		default:
+			t.status = status
+		}
+	}
+	t.updatedAt = now
+	return nil
+}
+
+func (s *Server) updateEvent(u *user, e *event, input map[string]any) error {
+	if desc, ok := input["description"].(string); ok {
+		e.description = &desc
+	}
+
+	startAt, err := argTime(input, "startAt")
+	if err != nil {
+		return err
+	}
+	if startAt != nil {
+		e.startAt = *startAt
+	}
+
+	if _, given := input["taskID"]; given && input["taskID"] != nil {
+		t, err := s.findTask(u, map[string]any{"id": input["taskID"]})
+		if err != nil {
+			return err
+		}
+		if u.runningEvent == e {
+			e.task.status = statusPaused
+			t.status = statusDoing
+			u.runningTask = t
+		}
+		e.task = t
+	}
+
+	endAt, err := argTime(input, "endAt")
+	if err != nil {
+		return err
+	}
+	if endAt != nil {
+		if endAt.Before(e.startAt) {
+			return errorWithCode(codeBadUserInput, "endAt must not be before startAt")
+		}
+		e.endAt = endAt
+		if u.runningEvent == e {
+			s.stopRunning(u, *endAt, statusPaused)
+		}
+	}
+	return nil
+}
+
+func (t *task) setDueDate(input map[string]any) error {
+	due, ok := input["dueDate"].(string)
+	if !ok {
+		return nil
+	}
+	if due == "" {
+		t.dueDate = nil
+		return nil
+	}
+
+	d, err := time.Parse(time.DateOnly, due)
+	if err != nil {
+		if d, err = time.Parse(time.RFC3339Nano, due); err != nil {
+			return errorWithCode(codeBadUserInput, "dueDate expects format of 2006-01-02: "+due)
+		}
+	}
+	t.dueDate = &d
+	return nil
+}
+
+// setParent sets the parent by parentID of the input, 0 to clear it. Tasks can't be under themselves
+func (s *Server) setParent(u *user, t *task, input map[string]any) error {
+	if input["parentID"] == nil {
+		return nil
+	}
+	id, err := argID(input, "parentID")
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		t.parent = nil
+		return nil
+	}
+
+	parent, err := s.findTask(u, map[string]any{"id": input["parentID"]})
+	if err != nil {
+		return err
+	}
+	for p := parent; p != nil; p = p.parent {
+		if p == t {
+			return errorWithCode(codeBadUserInput, fmt.Sprintf("task(id=%d) can't be under itself", t.id))
+		}
+	}
+	t.parent = parent
+	return nil
+}
+
+func (s *Server) findTask(u *user, args map[string]any) (*task, error) {
+	id, err := argID(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range s.tasks {
+		if t.id == id && t.user == u && !t.deleted {
+			return t, nil
+		}
+	}
+	return nil, errNotFound("task", id)
+}
+
+func (s *Server) findEvent(u *user, args map[string]any) (*event, error) {
+	id, err := argID(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range s.events {
+		if e.id == id && e.user == u && !e.deleted {
+			return e, nil
+		}
+	}
+	return nil, errNotFound("event", id)
+}
+
+// latestFirst sorts the events by startAt descending
+func latestFirst(events []*event) []*event {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].startAt.After(events[j].startAt)
+	})
+	return events
+}
+
+// page skips offset items, and keeps up to limit of the rest if limit > 0
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	if offset > 0 {
+		items = items[offset:]
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+func (s *Server) userObject(u *user) *object {
+	return &object{typeName: "User", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "id":
+			return u.id, nil
+		case "email":
+			return u.email, nil
+		case "name":
+			return u.name, nil
+		case "runningTaskID":
+			if u.runningTask == nil {
+				return nil, nil
+			}
+			return u.runningTask.id, nil
+		case "runningTask":
+			if u.runningTask == nil {
+				return nil, nil
+			}
+			return s.taskObject(u.runningTask), nil
+		case "runningEvent":
+			if u.runningEvent == nil {
+				return nil, nil
+			}
+			return s.eventObject(u.runningEvent), nil
+		}
+		return nil, fmt.Errorf("field %s not implemented", name)
+	}}
+}
+
+func (s *Server) taskObject(t *task) *object {
+	return &object{typeName: "Task", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "id":
+			return t.id, nil
+		case "name":
+			return t.name, nil
+		case "description":
+			return t.description, nil
+		case "status":
+			return t.status, nil
+		case "createdAt":
+			return t.createdAt, nil
+		case "updatedAt":
+			return t.updatedAt, nil
+		case "dueDate":
+			return t.dueDate, nil
+		case "parentID":
+			if t.parent == nil {
+				return nil, nil
+			}
+			return t.parent.id, nil
+		case "events":
+			var events []*event
+			for _, e := range s.events {
+				if e.task == t && !e.deleted {
+					events = append(events, e)
+				}
+			}
+			input := argObject(args, "input")
+			events = page(latestFirst(events), argInt(input, "limit"), argInt(input, "offset"))
+
+			res := make([]*object, len(events))
+			for i, e := range events {
+				res[i] = s.eventObject(e)
+			}
+			return res, nil
+		}
+		return nil, fmt.Errorf("field %s not implemented", name)
+	}}
+}
+
+func (s *Server) eventObject(e *event) *object {
+	return &object{typeName: "Event", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "id":
+			return e.id, nil
+		case "taskID":
+			return e.task.id, nil
+		case "startAt":
+			return e.startAt, nil
+		case "endAt":
+			return e.endAt, nil
+		case "description":
+			return e.description, nil
+		}
+		return nil, fmt.Errorf("field %s not implemented", name)
+	}}
+}
+
+// eventsResult gives the events, with the tasks they belong to
+func (s *Server) eventsResult(events []*event) *object {
+	return &object{typeName: "EventsResult", field: func(name string, args map[string]any) (any, error) {
+		switch name {
+		case "events":
+			res := make([]*object, len(events))
+			for i, e := range events {
+				res[i] = s.eventObject(e)
+			}
+			return res, nil
+		case "tasks":
+			var res []*object
+			seen := map[*task]bool{}
+			for _, e := range events {
+				if !seen[e.task] {
+					seen[e.task] = true
+					res = append(res, s.taskObject(e.task))
+				}
+			}
+			return res, nil
+		}
+		return nil, fmt.Errorf("field %s not implemented", name)
+	}}
+}
+
+func argObject(args map[string]any, name string) map[string]any {
+	obj, _ := args[name].(map[string]any)
+	return obj
+}
+
+func argString(args map[string]any, name string) *string {
+	s, ok := args[name].(string)
+	if !ok {
+		return nil
+	}
+	return &s
+}
+
+func argInt(args map[string]any, name string) int {
+	f, _ := args[name].(float64)
+	return int(f)
+}
+
+// argID accepts IDs both as numbers & strings
+func argID(args map[string]any, name string) (int64, error) {
+	switch v := args[name].(type) {
+	case float64:
+		return int64(v), nil
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, errorWithCode(codeBadUserInput, fmt.Sprintf("%s is not a valid ID: %s", name, v))
+		}
+		return id, nil
+	}
+	return 0, errorWithCode(codeBadUserInput, name+" must be provided")
+}
+
+func argTime(args map[string]any, name string) (*time.Time, error) {
+	s, ok := args[name].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, errorWithCode(codeBadUserInput, fmt.Sprintf("%s is not a valid time: %s", name, s))
+	}
+	return &t, nil
+}
@@ -0,0 +1,141 @@
+package apitest
+
+// SDL is the schema served by the fake backend, covering the operations used by the api package
+const SDL = `
+scalar Time
+
+enum TaskStatus {
+  NOT_STARTED
+  DOING
+  DONE
+  PAUSED
+}
+
+type User {
+  id: Int!
+  email: String!
+  name: String!
+  runningTaskID: ID
+  runningTask: Task
+  runningEvent: Event
+}
+
+type Task {
+  id: ID!
+  name: String!
+  description: String!
+  status: TaskStatus!
+  createdAt: Time!
+  updatedAt: Time!
+  dueDate: Time
+  parentID: ID
+  events(input: TaskEventsInput): [Event!]!
+}
+
+type Event {
+  id: ID!
+  taskID: ID!
+  startAt: Time!
+  endAt: Time
+  description: String
+}
+
+type EventsResult {
+  events: [Event!]!
+  tasks: [Task!]!
+}
+
+type AuthPayload {
+  user: User!
+  token: String!
+}
+
+type TaskStartResult {
+  task: Task!
+  event: Event
+}
+
+input TaskEventsInput {
+  limit: Int
+  offset: Int
+}
+
+enum TaskSort {
+  DUE_DATE
+  CREATED_AT
+  UPDATED_AT
+  NAME
+}
+
+input QueryTaskInput {
+  status: [TaskStatus!]
+  search: String
+  regex: String
+  dueBefore: Time
+  dueAfter: Time
+  createdBefore: Time
+  createdAfter: Time
+  updatedBefore: Time
+  updatedAfter: Time
+  overdue: Boolean
+  eventsBefore: Time
+  eventsAfter: Time
+  parentID: ID
+  sort: TaskSort
+  reverse: Boolean
+  limit: Int
+  offset: Int
+}
+
+input TaskCreateInput {
+  name: String!
+  description: String
+  dueDate: String
+  parentID: ID
+}
+
+input TaskUpdateInput {
+  name: String
+  description: String
+  status: TaskStatus
+  dueDate: String
+  parentID: ID
+}
+
+input TaskStartInput {
+  startAt: Time
+  description: String
+}
+
+input EventUpdateInput {
+  description: String
+  startAt: Time
+  endAt: Time
+  taskID: ID
+}
+
+input LoginInput {
+  email: String!
+  password: String!
+}
+
+type Query {
+  me: User!
+  tasks(input: QueryTaskInput): [Task!]!
+  task(id: ID!): Task!
+  events(since: Time!, days: Int!, limit: Int, offset: Int): EventsResult!
+  event(id: ID!): Event!
+}
+
+type Mutation {
+  login(input: LoginInput!): AuthPayload!
+  logout: Boolean!
+  taskCreate(input: TaskCreateInput!): Task!
+  taskStart(id: ID!, input: TaskStartInput): TaskStartResult!
+  taskUpdate(id: ID!, input: TaskUpdateInput!): Task!
+  taskDelete(id: ID!): Task!
+  taskUndelete(id: ID!): Task!
+  eventUpdate(id: ID!, input: EventUpdateInput!): Event!
+  eventDelete(id: ID!): Event!
+}
+`
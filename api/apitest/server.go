@@ -0,0 +1,321 @@
+// Package apitest provides a fake Todopeer backend, running on httptest with in-memory state.
+// It serves the operations used by the api package, for testing without the real backend:
+//
+//	srv := apitest.NewServer()
+//	defer srv.Close()
+//	token := srv.AddUser("me@example.com", "password", "Me")
+//	client := api.NewClient(token, api.WithEndpoint(srv.Endpoint()))
+package apitest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/todopeer/cli/util/gql"
+)
+
+const (
+	codeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	codeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	codeUnauthenticated  = "UNAUTHENTICATED"
+	codeNotFound         = "NOT_FOUND"
+	codeBadUserInput     = "BAD_USER_INPUT"
+)
+
+// Server is the fake backend. The GraphQL endpoint is at Endpoint()
+type Server struct {
+	*httptest.Server
+
+	// Now is the clock of the server, default to time.Now
+	Now func() time.Time
+
+	schema *gql.Schema
+
+	mu     sync.Mutex
+	nextID int64
+	users  []*user
+	tokens map[string]*user
+	tasks  []*task
+	events []*event
+}
+
+// NewServer starts a fake backend. Close it when done
+func NewServer() *Server {
+	return NewServerWithSDL(SDL)
+}
+
+// NewServerWithSDL starts a fake backend of the schema, like SDL with fields removed as in older backends.
+// Queries of the fields missing are rejected by validation. Close it when done
+func NewServerWithSDL(sdl string) *Server {
+	schema, err := newSchema(sdl)
+	if err != nil {
+		panic("apitest: invalid schema: " + err.Error())
+	}
+
+	s := &Server{
+		Now:    time.Now,
+		schema: schema,
+		tokens: map[string]*user{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/query", s.serveQuery)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Endpoint is the GraphQL endpoint, to be used with api.WithEndpoint
+func (s *Server) Endpoint() string {
+	return s.URL + "/query"
+}
+
+// AddUser registers a user, and returns a token logged in as the user
+func (s *Server) AddUser(email, password, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := &user{id: len(s.users) + 1, email: email, password: password, name: name}
+	s.users = append(s.users, u)
+	return s.newToken(u)
+}
+
+func (s *Server) newToken(u *user) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+	s.tokens[token] = u
+	return token
+}
+
+func (s *Server) genID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+type gqlError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *gqlError) Error() string {
+	return e.Message
+}
+
+func errorWithCode(code, message string) *gqlError {
+	return &gqlError{Message: message, Extensions: map[string]any{"code": code}}
+}
+
+type response struct {
+	Data   any         `json:"data"`
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp *response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) serveQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Query         string         `json:"query"`
+		Variables     map[string]any `json:"variables"`
+		OperationName string         `json:"operationName"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		writeResponse(w, http.StatusBadRequest, &response{Errors: []*gqlError{errorWithCode(codeBadUserInput, "invalid request body: "+err.Error())}})
+		return
+	}
+	normalizeNumbers(req.Variables)
+
+	doc, err := gql.Parse(req.Query)
+	if err != nil {
+		writeResponse(w, http.StatusUnprocessableEntity, &response{Errors: []*gqlError{errorWithCode(codeParseFailed, err.Error())}})
+		return
+	}
+	op, err := doc.Operation(req.OperationName)
+	if err != nil {
+		writeResponse(w, http.StatusUnprocessableEntity, &response{Errors: []*gqlError{errorWithCode(codeValidationFailed, err.Error())}})
+		return
+	}
+
+	v := &validator{schema: s.schema, doc: doc, vars: req.Variables}
+	if errs := v.operation(op); len(errs) > 0 {
+		writeResponse(w, http.StatusUnprocessableEntity, &response{Errors: errs})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ex := &executor{doc: doc, vars: req.Variables}
+	root := s.queryRoot(s.userOf(r))
+	if op.Type == "mutation" {
+		root = s.mutationRoot(s.userOf(r))
+	}
+	data := ex.selectionSet(root, op.SelectionSet, nil)
+	writeResponse(w, http.StatusOK, &response{Data: data, Errors: ex.errs})
+}
+
+func (s *Server) userOf(r *http.Request) *user {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.tokens[token]
+}
+
+// normalizeNumbers turns json.Number into float64, the same as values parsed from the query
+func normalizeNumbers(m map[string]any) {
+	for k, v := range m {
+		m[k] = normalizeNumber(v)
+	}
+}
+
+func normalizeNumber(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		normalizeNumbers(v)
+	case []any:
+		for i := range v {
+			v[i] = normalizeNumber(v[i])
+		}
+	}
+	return v
+}
+
+// object resolves the fields of a GraphQL object
+type object struct {
+	typeName string
+	field    func(name string, args map[string]any) (any, error)
+}
+
+// orderedMap keeps the fields in the order of selection, when encoded as JSON
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, found := m.values[key]; !found {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, _ := json.Marshal(k)
+		buf.Write(kb)
+		buf.WriteByte(':')
+
+		vb, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type executor struct {
+	doc  *gql.Document
+	vars map[string]any
+	errs []*gqlError
+}
+
+func (ex *executor) selectionSet(obj *object, sels []gql.Selection, path []any) *orderedMap {
+	res := &orderedMap{values: map[string]any{}}
+	ex.collect(res, obj, sels, path)
+	return res
+}
+
+func (ex *executor) collect(res *orderedMap, obj *object, sels []gql.Selection, path []any) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *gql.Field:
+			key := sel.ResponseKey()
+			if sel.Name == "__typename" {
+				res.set(key, obj.typeName)
+				continue
+			}
+
+			args := map[string]any{}
+			for _, arg := range sel.Arguments {
+				args[arg.Name] = arg.Value.Resolve(ex.vars)
+			}
+
+			fieldPath := append(append([]any{}, path...), key)
+			value, err := obj.field(sel.Name, args)
+			if err != nil {
+				ex.addError(err, fieldPath)
+				res.set(key, nil)
+				continue
+			}
+			res.set(key, ex.complete(value, sel.SelectionSet, fieldPath))
+		case *gql.InlineFragment:
+			if sel.TypeCondition == "" || sel.TypeCondition == obj.typeName {
+				ex.collect(res, obj, sel.SelectionSet, path)
+			}
+		case *gql.FragmentSpread:
+			if f := ex.doc.Fragments[sel.Name]; f != nil && f.TypeCondition == obj.typeName {
+				ex.collect(res, obj, f.SelectionSet, path)
+			}
+		}
+	}
+}
+
+func (ex *executor) complete(value any, sels []gql.Selection, path []any) any {
+	switch value := value.(type) {
+	case *object:
+		if value == nil {
+			return nil
+		}
+		return ex.selectionSet(value, sels, path)
+	case []*object:
+		res := make([]any, len(value))
+		for i, item := range value {
+			res[i] = ex.complete(item, sels, append(append([]any{}, path...), i))
+		}
+		return res
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano)
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return value.UTC().Format(time.RFC3339Nano)
+	default:
+		return value
+	}
+}
+
+func (ex *executor) addError(err error, path []any) {
+	e, ok := err.(*gqlError)
+	if !ok {
+		e = &gqlError{Message: err.Error()}
+	}
+	e.Path = path
+	ex.errs = append(ex.errs, e)
+}
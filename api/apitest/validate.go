@@ -0,0 +1,172 @@
+package apitest
+
+import (
+	"fmt"
+
+	"github.com/todopeer/cli/util/gql"
+)
+
+// validator checks the operation against the schema, the way the backend rejects a request before running it
+type validator struct {
+	schema *gql.Schema
+	doc    *gql.Document
+	vars   map[string]any
+	errs   []*gqlError
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.errs = append(v.errs, &gqlError{
+		Message:    fmt.Sprintf(format, args...),
+		Extensions: map[string]any{"code": codeValidationFailed},
+	})
+}
+
+func (v *validator) operation(op *gql.Operation) []*gqlError {
+	root := v.schema.RootType(op.Type)
+	if root == nil {
+		v.errorf("schema does not support operation type %q", op.Type)
+		return v.errs
+	}
+
+	for _, def := range op.Variables {
+		if v.schema.Types[def.Type.NamedType()] == nil {
+			v.errorf("Unknown type %q.", def.Type.NamedType())
+			continue
+		}
+		value, found := v.vars[def.Name]
+		if !found && def.Default != nil {
+			continue
+		}
+		v.input(value, def.Type, "$"+def.Name)
+	}
+
+	v.selectionSet(op.SelectionSet, root)
+	return v.errs
+}
+
+func (v *validator) selectionSet(sels []gql.Selection, t *gql.TypeDef) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *gql.Field:
+			v.field(sel, t)
+		case *gql.InlineFragment:
+			v.selectionSet(sel.SelectionSet, v.typeCondition(sel.TypeCondition, t))
+		case *gql.FragmentSpread:
+			f, found := v.doc.Fragments[sel.Name]
+			if !found {
+				v.errorf("Unknown fragment %q.", sel.Name)
+				continue
+			}
+			v.selectionSet(f.SelectionSet, v.typeCondition(f.TypeCondition, t))
+		}
+	}
+}
+
+func (v *validator) typeCondition(name string, t *gql.TypeDef) *gql.TypeDef {
+	if name == "" {
+		return t
+	}
+	if cond := v.schema.Types[name]; cond != nil {
+		return cond
+	}
+	v.errorf("Unknown type %q.", name)
+	return t
+}
+
+func (v *validator) field(f *gql.Field, t *gql.TypeDef) {
+	if f.Name == "__typename" {
+		return
+	}
+
+	def := t.Field(f.Name)
+	if def == nil {
+		v.errorf("Cannot query field %q on type %q.", f.Name, t.Name)
+		return
+	}
+
+	for _, arg := range f.Arguments {
+		argDef := def.Arg(arg.Name)
+		if argDef == nil {
+			v.errorf("Unknown argument %q on field %q.", arg.Name, t.Name+"."+f.Name)
+			continue
+		}
+		// variables are checked against their definitions
+		if arg.Value.Kind != gql.ValueVariable {
+			v.input(arg.Value.Resolve(v.vars), argDef.Type, t.Name+"."+f.Name+"("+arg.Name+")")
+		}
+	}
+	for _, argDef := range def.Args {
+		if argDef.Type.NonNull && argDef.Default == nil && f.Argument(argDef.Name) == nil {
+			v.errorf("Field %q argument %q of type %q is required, but it was not provided.", f.Name, argDef.Name, argDef.Type)
+		}
+	}
+
+	fieldType := v.schema.Types[def.Type.NamedType()]
+	if fieldType == nil {
+		return
+	}
+	isLeaf := fieldType.Kind == gql.KindScalar || fieldType.Kind == gql.KindEnum
+	switch {
+	case isLeaf && len(f.SelectionSet) > 0:
+		v.errorf("Field %q must not have a selection since type %q has no subfields.", f.Name, def.Type)
+	case !isLeaf && len(f.SelectionSet) == 0:
+		v.errorf("Field %q of type %q must have a selection of subfields.", f.Name, def.Type)
+	default:
+		v.selectionSet(f.SelectionSet, fieldType)
+	}
+}
+
+// input checks an input value, as decoded from JSON, against its type
+func (v *validator) input(value any, t *gql.TypeRef, path string) {
+	if value == nil {
+		if t.NonNull {
+			v.errorf("%s: must not be null.", path)
+		}
+		return
+	}
+
+	if t.Elem != nil {
+		list, ok := value.([]any)
+		if !ok {
+			// a single value is coerced into a list
+			v.input(value, t.Elem, path)
+			return
+		}
+		for i, item := range list {
+			v.input(item, t.Elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return
+	}
+
+	def := v.schema.Types[t.Name]
+	if def == nil {
+		return
+	}
+
+	switch def.Kind {
+	case gql.KindEnum:
+		s, ok := value.(string)
+		if !ok || def.EnumValue(s) == nil {
+			v.errorf("%s: %v is not a valid value of enum %q.", path, value, def.Name)
+		}
+	case gql.KindInputObject:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.errorf("%s: expect an object of type %q.", path, def.Name)
+			return
+		}
+		for name, fieldValue := range obj {
+			fieldDef := def.InputField(name)
+			if fieldDef == nil {
+				v.errorf("%s: unknown field %q of type %q.", path, name, def.Name)
+				continue
+			}
+			v.input(fieldValue, fieldDef.Type, path+"."+name)
+		}
+		for _, fieldDef := range def.InputFields {
+			if _, given := obj[fieldDef.Name]; !given && fieldDef.Type.NonNull && fieldDef.Default == nil {
+				v.errorf("%s: field %q of type %q is required.", path, fieldDef.Name, def.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Cache keeps the results of queries, to read them without a round trip. See WithCache
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte) error
+	// Clear drops all the entries, as mutations make them stale
+	Clear() error
+}
+
+type CacheMode int
+
+const (
+	// CacheFresh always queries the backend, filling the cache with the results
+	CacheFresh CacheMode = iota
+	// CacheFirst answers from the cache when possible, refreshing it in the background. See Client.Wait
+	CacheFirst
+)
+
+// WithCache makes QueryTasks, QueryEvents & GetTaskEvents go through the cache. Mutations clear it
+func WithCache(cache Cache, mode CacheMode) ClientOptionFunc {
+	return func(o *clientOption) {
+		o.cache = cache
+		o.cacheMode = mode
+	}
+}
+
+// Wait blocks until the cache refreshes in the background are done
+func (c *Client) Wait() {
+	c.refreshing.Wait()
+}
+
+// cacheKey tells queries apart by their type, which carries the graphql tags, and the variables
+func cacheKey(q any, variables map[string]any) string {
+	vars, _ := json.Marshal(variables)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%T%s", q, vars)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedQuery is Query, going through the cache if the client has one
+func (c *Client) cachedQuery(ctx context.Context, q any, variables map[string]any) error {
+	if c.cache == nil {
+		return c.Query(ctx, q, variables)
+	}
+
+	key := cacheKey(q, variables)
+	if c.cacheMode == CacheFirst {
+		if b, found := c.cache.Get(key); found && json.Unmarshal(b, q) == nil {
+			c.refreshing.Add(1)
+			go func() {
+				defer c.refreshing.Done()
+
+				fresh := reflect.New(reflect.TypeOf(q).Elem()).Interface()
+				if err := c.fill(ctx, key, fresh, variables); err != nil && logFunc != nil {
+					logFunc("refreshing cache: %v", err)
+				}
+			}()
+			return nil
+		}
+	}
+	return c.fill(ctx, key, q, variables)
+}
+
+// fill runs the query, and caches the result unless a mutation happened meanwhile
+func (c *Client) fill(ctx context.Context, key string, q any, variables map[string]any) error {
+	gen := c.cacheGen.Load()
+	if err := c.Query(ctx, q, variables); err != nil {
+		return err
+	}
+	if c.cacheGen.Load() != gen {
+		return nil
+	}
+
+	b, err := json.Marshal(q)
+	if err == nil {
+		err = c.cache.Set(key, b)
+	}
+	if err != nil && logFunc != nil {
+		// failing to cache doesn't fail the query
+		logFunc("caching query: %v", err)
+	}
+	return nil
+}
+
+// invalidateCache is called on mutations
+func (c *Client) invalidateCache() {
+	if c.cache == nil {
+		return
+	}
+
+	c.cacheGen.Add(1)
+	if err := c.cache.Clear(); err != nil && logFunc != nil {
+		logFunc("clearing cache: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrorKind classifies an Error. The kinds are errors themselves, to be used with errors.Is:
+//
+//	errors.Is(err, api.ErrUnauthenticated)
+type ErrorKind string
+
+func (k ErrorKind) Error() string {
+	return string(k)
+}
+
+const (
+	ErrUnauthenticated ErrorKind = "unauthenticated"
+	ErrNotFound        ErrorKind = "not found"
+	ErrValidation      ErrorKind = "validation error"
+	ErrNetwork         ErrorKind = "network error"
+	ErrServer          ErrorKind = "server error"
+)
+
+// Error is returned by the Client when a request fails
+type Error struct {
+	Kind ErrorKind
+	// Code is the error code given by server, or the HTTP status when there's no GraphQL error
+	Code string
+	// Path of the field that failed, like "task.events"
+	Path    string
+	Message string
+	// Err is the underlying error, if any
+	Err error
+
+	// unsent is set on network errors of requests that never reached the backend, so couldn't have been applied
+	unsent bool
+}
+
+func (e *Error) Error() string {
+	var details []string
+	if e.Code != "" {
+		details = append(details, "code: "+e.Code)
+	}
+	if e.Path != "" {
+		details = append(details, "path: "+e.Path)
+	}
+
+	if len(details) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(details, ", "))
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+type gqlError struct {
+	Message    string `json:"message"`
+	Path       []any  `json:"path"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+func (e *gqlError) path() string {
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// call keeps what the transport saw of a request, to build the Error from
+type call struct {
+	op     operation
+	status int
+	errors []gqlError
+	// sent is set once any attempt wrote the request, see roundTripWithRetry
+	sent atomic.Bool
+}
+
+type callKey struct{}
+
+func withCall(ctx context.Context, c *call) context.Context {
+	return context.WithValue(ctx, callKey{}, c)
+}
+
+func callFrom(ctx context.Context) *call {
+	c, _ := ctx.Value(callKey{}).(*call)
+	return c
+}
+
+// record keeps the status & GraphQL errors of the response. The body is restored for the graphql client to decode
+func (c *call) record(resp *http.Response) error {
+	c.status = resp.StatusCode
+
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	var out struct {
+		Errors []gqlError `json:"errors"`
+	}
+	if json.Unmarshal(b, &out) == nil {
+		c.errors = out.Errors
+	}
+	return nil
+}
+
+// toError converts the error from the graphql client into an *Error
+func (c *call) toError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if len(c.errors) > 0 {
+		e := &c.errors[0]
+		return &Error{
+			Kind:    kindOfCode(e.Extensions.Code, e.Message),
+			Code:    e.Extensions.Code,
+			Path:    e.path(),
+			Message: e.Message,
+			Err:     err,
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// canceled by the caller, nothing went wrong with the backend
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: ErrNetwork, Message: "request timed out", Err: err, unsent: !c.sent.Load()}
+	case c.status == 0:
+		return &Error{Kind: ErrNetwork, Message: err.Error(), Err: err, unsent: !c.sent.Load()}
+	case c.status != http.StatusOK:
+		return &Error{Kind: kindOfStatus(c.status), Code: strconv.Itoa(c.status), Message: http.StatusText(c.status), Err: err}
+	default:
+		return &Error{Kind: ErrServer, Message: "invalid response: " + err.Error(), Err: err}
+	}
+}
+
+// isUnsent tells whether err is a network error of a request that never reached the backend. Requests failing
+// after being sent, like on timeouts waiting for the response, may have been applied all the same
+func isUnsent(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ErrNetwork && e.unsent
+}
+
+func kindOfCode(code, message string) ErrorKind {
+	switch code {
+	case "UNAUTHENTICATED", "UNAUTHORIZED", "FORBIDDEN":
+		return ErrUnauthenticated
+	case "NOT_FOUND":
+		return ErrNotFound
+	case "GRAPHQL_VALIDATION_FAILED", "GRAPHQL_PARSE_FAILED", "BAD_USER_INPUT", "VALIDATION_FAILED":
+		return ErrValidation
+	}
+
+	// the backend reports some errors by message only
+	message = strings.ToLower(message)
+	switch {
+	case message == "access denied":
+		return ErrUnauthenticated
+	case strings.Contains(message, "not found"):
+		return ErrNotFound
+	}
+	return ErrServer
+}
+
+func kindOfStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrUnauthenticated
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return ErrValidation
+	default:
+		return ErrServer
+	}
+}
@@ -0,0 +1,80 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/api/apitest"
+)
+
+func TestErrorKinds(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	client := clientOf(srv)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	noRetry := api.WithRetry(api.RetryPolicy{})
+
+	for _, tc := range []struct {
+		name string
+		call func(ctx context.Context) error
+		want api.ErrorKind
+	}{
+		{"unauthenticated", func(ctx context.Context) error {
+			_, err := api.NewClient("invalid", api.WithEndpoint(srv.Endpoint())).Me(ctx)
+			return err
+		}, api.ErrUnauthenticated},
+		{"not found", func(ctx context.Context) error {
+			_, _, err := client.GetTaskEvents(ctx, 9999)
+			return err
+		}, api.ErrNotFound},
+		{"validation", func(ctx context.Context) error {
+			_, err := client.Raw(ctx, "{ me { unknownField } }", nil, "")
+			return err
+		}, api.ErrValidation},
+		{"network", func(ctx context.Context) error {
+			_, err := api.NewClient("token", api.WithEndpoint(down.URL), noRetry).Me(ctx)
+			return err
+		}, api.ErrNetwork},
+		{"server", func(ctx context.Context) error {
+			_, err := api.NewClient("token", api.WithEndpoint(failing.URL), noRetry).Me(ctx)
+			return err
+		}, api.ErrServer},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call(context.Background())
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want kind %s", err, tc.want)
+			}
+			var apiErr *api.Error
+			if !errors.As(err, &apiErr) || apiErr.Kind != tc.want {
+				t.Errorf("err = %#v, want an *api.Error of kind %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestErrorCanceled(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// canceled by the caller, it's no error of the backend
+	_, err := client.Me(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		t.Errorf("err = %v, want no *api.Error", err)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -9,25 +10,33 @@ import (
 )
 
 type Event struct {
-	ID          ID
-	TaskID      ID `graphql:"taskID"`
-	StartAt     Time
-	EndAt       *Time
-	Description *graphql.String
+	ID          ID              `json:"id"`
+	TaskID      ID              `json:"taskID" graphql:"taskID"`
+	StartAt     Time            `json:"startAt"`
+	EndAt       *Time           `json:"endAt"`
+	Description *graphql.String `json:"description"`
 }
 
 type EventFormatter struct {
 	Prefix          string
 	WithDate        bool
 	DurationFromNow *time.Time
+	// Humanize shows the start by its day, like "yesterday 14:05", and running events like "started 1h12m ago"
+	Humanize bool
+	// Location is the time zone to show the times in
+	Location *time.Location
 }
 
 func (f EventFormatter) Output(e *Event) {
 	fmt.Printf("%s[%d]", f.Prefix, e.ID)
-	if f.WithDate {
-		fmt.Print(e.StartAt.DateOnly(), " ")
+	if f.Humanize {
+		fmt.Print(e.Humanized(time.Now(), f.Location))
+	} else {
+		if f.WithDate {
+			fmt.Print(e.StartAt.DateOnly(f.Location), " ")
+		}
+		fmt.Printf("%s - %s", e.StartAt.EventTimeOnly(f.Location), e.EndAt.EventTimeOnly(f.Location))
 	}
-	fmt.Printf("%s - %s", e.StartAt.EventTimeOnly(), e.EndAt.EventTimeOnly())
 	if f.DurationFromNow != nil {
 		end := (*time.Time)(e.EndAt)
 		if end == nil {
@@ -43,12 +52,26 @@ func (f EventFormatter) Output(e *Event) {
 	}
 }
 
+// Humanized formats the time span of the event relative to now: like "started 1h12m ago" while running, otherwise
+// like "yesterday 14:05 - 15:10", in the time zone
+func (e *Event) Humanized(now time.Time, loc *time.Location) string {
+	if e.EndAt == nil {
+		return "started " + e.StartAt.Relative(now)
+	}
+
+	start, end := time.Time(e.StartAt).In(loc), time.Time(*e.EndAt).In(loc)
+	if start.YearDay() == end.YearDay() && start.Year() == end.Year() {
+		return dt.Day(start, now, loc) + " - " + end.Format("15:04")
+	}
+	return dt.Day(start, now, loc) + " - " + dt.Day(end, now, loc)
+}
+
 type QueryEventsResult struct {
 	Events []Event
 	Tasks  []Task
 }
 
-func (c *Client) GetEvent(eventID ID) (*Event, error) {
+func (c *Client) GetEvent(ctx context.Context, eventID ID) (*Event, error) {
 	query := struct {
 		Event `graphql:"event(id:$id)"`
 	}{}
@@ -56,7 +79,7 @@ func (c *Client) GetEvent(eventID ID) (*Event, error) {
 		"id": eventID,
 	}
 
-	err := c.Query(&query, variables)
+	err := c.Query(ctx, &query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -69,12 +92,12 @@ type QueryRunningEventReuslt struct {
 	RunningEvent *Event `graphql:"runningEvent"`
 }
 
-func (c *Client) QueryRunningEvent() (event *Event, err error) {
+func (c *Client) QueryRunningEvent(ctx context.Context) (event *Event, err error) {
 	query := struct {
 		QueryRunningEventReuslt `graphql:"me"`
 	}{}
 
-	err = c.Query(&query, nil)
+	err = c.Query(ctx, &query, nil)
 	if err != nil {
 		return
 	}
@@ -82,19 +105,21 @@ func (c *Client) QueryRunningEvent() (event *Event, err error) {
 	return query.RunningEvent, nil
 }
 
-func (c *Client) QueryLatestEvents() (*Event, error) {
+// QueryLatestEvents gives the latest event within the lookback days, see WithLookback. Nil if there's none
+func (c *Client) QueryLatestEvents(ctx context.Context) (*Event, error) {
 	query := struct {
-		QueryEventsResult `graphql:"events(since:$since, days:3, limit:2)"`
+		QueryEventsResult `graphql:"events(since:$since, days:$days, limit:2)"`
 	}{}
 
-	// only care about event in recent 2 days
-	since := time.Now().AddDate(0, 0, -2)
+	// only care about event in recent days
+	since := time.Now().AddDate(0, 0, -c.lookbackDays)
 
 	variables := map[string]interface{}{
 		"since": since,
+		"days":  graphql.Int(c.lookbackDays + 1),
 	}
 
-	err := c.Query(&query, variables)
+	err := c.Query(ctx, &query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +131,7 @@ func (c *Client) QueryLatestEvents() (*Event, error) {
 	return &query.Events[0], nil
 }
 
-func (c *Client) QueryEvents(since time.Time, days int) (*QueryEventsResult, error) {
+func (c *Client) QueryEvents(ctx context.Context, since time.Time, days int) (*QueryEventsResult, error) {
 	query := struct {
 		QueryEventsResult `graphql:"events(since:$since, days: $days)"`
 	}{}
@@ -115,7 +140,33 @@ func (c *Client) QueryEvents(since time.Time, days int) (*QueryEventsResult, err
 		"days":  graphql.Int(days),
 	}
 
-	err := c.Query(&query, variables)
+	err := c.cachedQuery(ctx, &query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &query.QueryEventsResult, nil
+}
+
+// QueryEventsPage queries the events in the days since, latest first. It skips offset events, and returns up to
+// limit of the rest if limit > 0. Tasks are the ones of the events returned.
+// Without limit & offset, it's QueryEvents: servers not supporting paging reject these args
+func (c *Client) QueryEventsPage(ctx context.Context, since time.Time, days, limit, offset int) (*QueryEventsResult, error) {
+	if limit == 0 && offset == 0 {
+		return c.QueryEvents(ctx, since, days)
+	}
+
+	query := struct {
+		QueryEventsResult `graphql:"events(since:$since, days: $days, limit: $limit, offset: $offset)"`
+	}{}
+	variables := map[string]interface{}{
+		"since":  since,
+		"days":   graphql.Int(days),
+		"limit":  optionalInt(limit),
+		"offset": optionalInt(offset),
+	}
+
+	err := c.cachedQuery(ctx, &query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +174,7 @@ func (c *Client) QueryEvents(since time.Time, days int) (*QueryEventsResult, err
 	return &query.QueryEventsResult, nil
 }
 
-func (c *Client) DeleteEvent(eventID ID) (*Event, error) {
+func (c *Client) DeleteEvent(ctx context.Context, eventID ID) (*Event, error) {
 	var mutation struct {
 		Event `graphql:"eventDelete(id: $id)"`
 	}
@@ -132,7 +183,7 @@ func (c *Client) DeleteEvent(eventID ID) (*Event, error) {
 		"id": eventID,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	err := c.Mutate(ctx, &mutation, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +198,7 @@ type EventUpdateInput struct {
 	TaskID      *ID             `json:"taskID"`
 }
 
-func (c *Client) UpdateEvent(eventID ID, input EventUpdateInput) (*Event, error) {
+func (c *Client) UpdateEvent(ctx context.Context, eventID ID, input EventUpdateInput) (*Event, error) {
 	var mutation struct {
 		Event `graphql:"eventUpdate(id:$id, input: $input)"`
 	}
@@ -157,7 +208,8 @@ func (c *Client) UpdateEvent(eventID ID, input EventUpdateInput) (*Event, error)
 		"input": input,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	// setting the same values again is harmless
+	err := c.Mutate(RetrySafe(ctx), &mutation, variables)
 	if err != nil {
 		return nil, err
 	}
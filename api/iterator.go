@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shurcooL/graphql"
+)
+
+// DefaultPageSize is how many items the iterators fetch per request, unless told otherwise
+const DefaultPageSize = 100
+
+// optionalInt is for Int arguments where 0 means unset
+func optionalInt(n int) *graphql.Int {
+	if n == 0 {
+		return nil
+	}
+	v := graphql.Int(n)
+	return &v
+}
+
+// pager fetches the items a page at a time, as Next advances past the ones fetched
+type pager[T any] struct {
+	fetch    func(ctx context.Context, limit, offset int) ([]T, error)
+	pageSize int
+	// limit caps the items in total if > 0
+	limit  int
+	offset int
+
+	page []T
+	i    int
+	done bool
+	err  error
+}
+
+func newPager[T any](pageSize, limit, offset int, fetch func(ctx context.Context, limit, offset int) ([]T, error)) pager[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return pager[T]{fetch: fetch, pageSize: pageSize, limit: limit, offset: offset}
+}
+
+func (p *pager[T]) next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	p.i++
+	if p.i < len(p.page) {
+		return true
+	}
+	if p.done {
+		return false
+	}
+
+	size := p.pageSize
+	if p.limit > 0 && p.limit < size {
+		size = p.limit
+	}
+	p.page, p.err = p.fetch(ctx, size, p.offset)
+	if p.err != nil {
+		p.page = nil
+		return false
+	}
+	p.i = 0
+	p.offset += len(p.page)
+
+	// a short page is the last one
+	p.done = len(p.page) < size
+	if p.limit > 0 {
+		p.limit -= len(p.page)
+		p.done = p.done || p.limit <= 0
+	}
+	return len(p.page) > 0
+}
+
+func (p *pager[T]) current() T {
+	return p.page[p.i]
+}
+
+// TaskIterator goes through the tasks of a query, fetching a page per request as it advances:
+//
+//	it := client.IterateTasks(input, 0)
+//	for it.Next(ctx) {
+//		task := it.Task()
+//	}
+//	if err := it.Err(); err != nil {
+type TaskIterator struct {
+	pager[*Task]
+}
+
+// IterateTasks iterates the tasks matching input, pageSize at a time (DefaultPageSize if 0).
+// It starts at input.Offset, and stops after input.Limit tasks if that's set.
+// If the server rejects the filters or sorting, they're applied on the client instead, after loading all the tasks
+func (c *Client) IterateTasks(input QueryTaskInput, pageSize int) *TaskIterator {
+	// set when falling back to filtering on the client
+	var local []*Task
+	var isLocal bool
+
+	return &TaskIterator{newPager(pageSize, input.Limit, input.Offset, func(ctx context.Context, limit, offset int) ([]*Task, error) {
+		if !isLocal {
+			input.Limit, input.Offset = limit, offset
+			tasks, err := c.QueryTasks(ctx, input)
+			if !errors.Is(err, ErrValidation) || !input.needsServer() {
+				return tasks, err
+			}
+
+			if local, err = c.queryTasksLocally(ctx, input); err != nil {
+				return nil, err
+			}
+			isLocal = true
+		}
+		return page(local, limit, offset), nil
+	})}
+}
+
+// Next advances to the next task, fetching the next page if needed. It's false when done, or on error
+func (it *TaskIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Task is the current task
+func (it *TaskIterator) Task() *Task {
+	return it.current()
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *TaskIterator) Err() error {
+	return it.err
+}
+
+// EventIterator goes through the events in a time range, latest first, fetching a page per request as it advances
+type EventIterator struct {
+	pager[Event]
+	tasks map[ID]*Task
+}
+
+// IterateEvents iterates the events in the days since, pageSize at a time (DefaultPageSize if 0).
+// If the server rejects paging, the events are all loaded at once instead
+func (c *Client) IterateEvents(since time.Time, days, pageSize int) *EventIterator {
+	// set when falling back to paging on the client
+	var local []Event
+	var isLocal bool
+
+	it := &EventIterator{tasks: map[ID]*Task{}}
+	it.pager = newPager(pageSize, 0, 0, func(ctx context.Context, limit, offset int) ([]Event, error) {
+		if !isLocal {
+			res, err := c.QueryEventsPage(ctx, since, days, limit, offset)
+			if !errors.Is(err, ErrValidation) {
+				if err != nil {
+					return nil, err
+				}
+				it.addTasks(res.Tasks)
+				return res.Events, nil
+			}
+
+			if res, err = c.QueryEvents(ctx, since, days); err != nil {
+				return nil, err
+			}
+			it.addTasks(res.Tasks)
+			local, isLocal = res.Events, true
+		}
+		return page(local, limit, offset), nil
+	})
+	return it
+}
+
+func (it *EventIterator) addTasks(tasks []Task) {
+	for i := range tasks {
+		it.tasks[tasks[i].ID] = &tasks[i]
+	}
+}
+
+// Next advances to the next event, fetching the next page if needed. It's false when done, or on error
+func (it *EventIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Event is the current event
+func (it *EventIterator) Event() *Event {
+	return &it.page[it.i]
+}
+
+// Task is the task of the current event, nil if the backend didn't return it
+func (it *EventIterator) Task() *Task {
+	return it.tasks[it.current().TaskID]
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *EventIterator) Err() error {
+	return it.err
+}
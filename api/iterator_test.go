@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/api/apitest"
+)
+
+// countRequests counts the requests the server gets from now on. Call it before the first request
+func countRequests(srv *apitest.Server) *atomic.Int32 {
+	var n atomic.Int32
+	next := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.Add(1)
+		next.ServeHTTP(w, r)
+	})
+	return &n
+}
+
+func iterateAll(t *testing.T, it *api.TaskIterator) []*api.Task {
+	t.Helper()
+	var res []*api.Task
+	for it.Next(context.Background()) {
+		res = append(res, it.Task())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iterating tasks: %v", err)
+	}
+	return res
+}
+
+func TestIterateTasksPaging(t *testing.T) {
+	client, srv := newTestClient(t)
+	requests := countRequests(srv)
+	created := createTasks(t, client, "1", "2", "3", "4", "5", "6", "7")
+	open := []api.TaskStatus{api.TaskStatusNotStarted}
+
+	for _, tc := range []struct {
+		name          string
+		limit, offset int
+		want          []*api.Task
+		requests      int32
+	}{
+		{"all", 0, 0, created, 3},
+		{"limit", 4, 0, created[:4], 2},
+		{"offset", 0, 5, created[5:], 1},
+		{"limit & offset", 4, 2, created[2:6], 2},
+		{"offset past the end", 0, 10, nil, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+
+			got := iterateAll(t, client.IterateTasks(api.QueryTaskInput{Status: open, Limit: tc.limit, Offset: tc.offset}, 3))
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", names(got), names(tc.want))
+			}
+			for i := range got {
+				if got[i].ID != tc.want[i].ID {
+					t.Fatalf("got %v, want %v", names(got), names(tc.want))
+				}
+			}
+			if n := requests.Load(); n != tc.requests {
+				t.Errorf("sent %d requests, want %d", n, tc.requests)
+			}
+		})
+	}
+}
+
+func TestIterateEventsPaging(t *testing.T) {
+	// backends before paging have no limit & offset
+	unpaged := strings.Replace(apitest.SDL, "days: Int!, limit: Int, offset: Int)", "days: Int!)", 1)
+	for name, sdl := range map[string]string{"server side": apitest.SDL, "local fallback": unpaged} {
+		t.Run(name, func(t *testing.T) {
+			srv := apitest.NewServerWithSDL(sdl)
+			defer srv.Close()
+			client := clientOf(srv)
+			ctx := context.Background()
+			tasks := createTasks(t, client, "a", "b")
+
+			// 5 events, alternating the tasks, an hour apart
+			for i := 0; i < 5; i++ {
+				offset := api.StartTaskWithOffset(time.Duration(5-i) * time.Hour)
+				if _, _, err := client.StartTask(ctx, tasks[i%2].ID, offset); err != nil {
+					t.Fatalf("StartTask: %v", err)
+				}
+			}
+
+			since := time.Now().AddDate(0, 0, -1)
+			if res, err := client.QueryEvents(ctx, since, 2); err != nil || len(res.Events) != 5 {
+				t.Fatalf("QueryEvents = %v, %v, want 5 events", res, err)
+			}
+
+			it := client.IterateEvents(since, 2, 2)
+			var starts []time.Time
+			for it.Next(ctx) {
+				if it.Task() == nil || it.Task().ID != it.Event().TaskID {
+					t.Errorf("task of event %d = %v, want task %d", it.Event().ID, it.Task(), it.Event().TaskID)
+				}
+				starts = append(starts, time.Time(it.Event().StartAt))
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("iterating events: %v", err)
+			}
+
+			if len(starts) != 5 {
+				t.Fatalf("got %d events, want 5", len(starts))
+			}
+			for i := 1; i < len(starts); i++ {
+				if !starts[i].Before(starts[i-1]) {
+					t.Errorf("events not latest first: %v", starts)
+				}
+			}
+		})
+	}
+}
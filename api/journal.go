@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shurcooL/graphql"
+)
+
+// ErrQueued is returned by mutations that couldn't reach the backend, and got journaled to be replayed later
+var ErrQueued = errors.New("backend unreachable, queued for sync")
+
+// ErrConflict is for queued mutations that no longer apply when replayed
+var ErrConflict = errors.New("conflict")
+
+type MutationKind string
+
+const (
+	MutationTaskStart  MutationKind = "taskStart"
+	MutationTaskUpdate MutationKind = "taskUpdate"
+)
+
+// CurrentTask as TaskID of a PendingMutation stands for the task a command picks when no ID is given:
+// the latest task for taskStart, the running task for taskUpdate. It's resolved at the time of replay
+const CurrentTask ID = 0
+
+// PendingMutation is a mutation journaled while the backend is unreachable
+type PendingMutation struct {
+	Kind   MutationKind `json:"kind"`
+	TaskID ID           `json:"taskID"`
+	// At is when the mutation was meant to happen
+	At Time `json:"at"`
+	// Description of the event started by taskStart, or of the event ended by taskUpdate
+	Description *string          `json:"description,omitempty"`
+	Input       *TaskUpdateInput `json:"input,omitempty"`
+}
+
+// Journal keeps the pending mutations, in the order they're issued
+type Journal interface {
+	// Load returns the pending mutations, oldest first
+	Load() ([]PendingMutation, error)
+	// Save replaces the pending mutations
+	Save(pending []PendingMutation) error
+}
+
+// ReplayResult is the outcome of replaying a pending mutation. Err is set if it's dropped as a conflict
+type ReplayResult struct {
+	PendingMutation
+	Task  *Task
+	Event *Event
+	Err   error
+}
+
+// WithJournal makes StartTask & UpdateTask journal the mutation when the backend is unreachable.
+// The pending mutations are replayed before the next request; onReplay, if not nil, is told about the results
+func WithJournal(journal Journal, onReplay func([]ReplayResult)) ClientOptionFunc {
+	return func(o *clientOption) {
+		o.journal = journal
+		o.onReplay = onReplay
+	}
+}
+
+type noQueueKey struct{}
+
+// NoQueue makes the mutations made with ctx fail when the backend is unreachable, instead of getting queued.
+// It's for callers queueing the mutation on their own, see Queue
+func NoQueue(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noQueueKey{}, true)
+}
+
+type replayingKey struct{}
+
+type noReplayKey struct{}
+
+// withoutReplay skips replaying the pending mutations before the requests made with ctx, like for Login
+func withoutReplay(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noReplayKey{}, true)
+}
+
+func isReplaying(ctx context.Context) bool {
+	replaying, _ := ctx.Value(replayingKey{}).(bool)
+	return replaying
+}
+
+// Queue appends the mutation to the journal, and returns ErrQueued on success
+func (c *Client) Queue(m PendingMutation) error {
+	if c.journal == nil {
+		return errors.New("no journal to queue mutations")
+	}
+	c.replayMu.Lock()
+	defer c.replayMu.Unlock()
+
+	pending, err := c.journal.Load()
+	if err != nil {
+		return err
+	}
+	if err = c.journal.Save(append(pending, m)); err != nil {
+		return err
+	}
+	return ErrQueued
+}
+
+// queueOnNetworkError queues m if err tells the backend was unreachable, with the request never sent. Otherwise err
+// is returned as is: requests failing once sent, like on timeouts, may have been applied, and replaying them would
+// apply them twice
+func (c *Client) queueOnNetworkError(ctx context.Context, err error, m PendingMutation) error {
+	noQueue, _ := ctx.Value(noQueueKey{}).(bool)
+	if c.journal == nil || noQueue || isReplaying(ctx) || !isUnsent(err) {
+		return err
+	}
+	return c.Queue(m)
+}
+
+// Pending lists the mutations waiting to be replayed
+func (c *Client) Pending() ([]PendingMutation, error) {
+	if c.journal == nil {
+		return nil, nil
+	}
+	return c.journal.Load()
+}
+
+// Replay sends the pending mutations in order, back-dated to the time they were issued.
+// Mutations the backend rejects are dropped & reported as conflicts. On other errors, like the backend being still
+// unreachable, replay stops with the error, keeping the rest pending
+func (c *Client) Replay(ctx context.Context) ([]ReplayResult, error) {
+	// background cache refreshes may come here at the same time
+	c.replayMu.Lock()
+	defer c.replayMu.Unlock()
+
+	pending, err := c.Pending()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, replayingKey{}, true)
+
+	var results []ReplayResult
+	for i, m := range pending {
+		res := ReplayResult{PendingMutation: m}
+		switch m.Kind {
+		case MutationTaskStart:
+			res.Task, res.Event, res.Err = c.replayStart(ctx, m)
+		case MutationTaskUpdate:
+			res.Task, res.Event, res.Err = c.replayUpdate(ctx, m)
+		default:
+			res.Err = fmt.Errorf("%w: unknown mutation %q", ErrConflict, m.Kind)
+		}
+
+		if res.Err != nil && !isConflict(res.Err) {
+			if saveErr := c.journal.Save(pending[i:]); saveErr != nil {
+				return results, saveErr
+			}
+			return results, res.Err
+		}
+		results = append(results, res)
+	}
+	return results, c.journal.Save(nil)
+}
+
+// isConflict tells whether the error is about the mutation itself, so retrying it later wouldn't help
+func isConflict(err error) bool {
+	return errors.Is(err, ErrConflict) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation)
+}
+
+// replayPending replays before other requests, so they're made on top of the queued changes. It's skipped without
+// a valid session, as the replay would fail for that alone.
+// Failing to replay doesn't fail the request, which goes on with the rest kept pending. Only mutations fail if the
+// backend is unreachable, so they get queued behind the pending ones
+func (c *Client) replayPending(ctx context.Context, op operation) error {
+	noReplay, _ := ctx.Value(noReplayKey{}).(bool)
+	if c.journal == nil || noReplay || isReplaying(ctx) || c.noSession.Load() || c.replayHeld.Load() {
+		return nil
+	}
+
+	results, err := c.Replay(ctx)
+	if len(results) > 0 && c.onReplay != nil {
+		c.onReplay(results)
+	}
+	if err == nil {
+		return nil
+	}
+	if logFunc != nil {
+		logFunc("replaying the pending mutations: %v", err)
+	}
+
+	switch {
+	case errors.Is(err, ErrNetwork):
+		if op == opMutate {
+			return &Error{Kind: ErrNetwork, Message: "backend unreachable replaying the queued changes: " + err.Error(), Err: err, unsent: true}
+		}
+	case errors.Is(err, ErrUnauthenticated):
+		c.noSession.Store(true)
+	default:
+		// it would fail the same for the other requests of the client, left to the next run or sync
+		c.replayHeld.Store(true)
+	}
+	return nil
+}
+
+func (c *Client) replayStart(ctx context.Context, m PendingMutation) (*Task, *Event, error) {
+	taskID := m.TaskID
+	if taskID == CurrentTask {
+		e, err := c.QueryLatestEvents(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if e == nil {
+			return nil, nil, fmt.Errorf("%w: no event run in recent %d days to pick the task from", ErrConflict, c.lookbackDays)
+		}
+		taskID = e.TaskID
+	}
+
+	options := []StartTaskOptionFunc{StartTaskWithOffset(time.Since(time.Time(m.At)))}
+	if m.Description != nil {
+		options = append(options, StartTaskWithDescription(*m.Description))
+	}
+	return c.StartTask(ctx, taskID, options...)
+}
+
+// replayUpdate updates the task. If that stops the running event, the event is ended at the time queued instead
+func (c *Client) replayUpdate(ctx context.Context, m PendingMutation) (*Task, *Event, error) {
+	_, running, event, err := c.MeWithTaskEvent(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskID := m.TaskID
+	if taskID == CurrentTask {
+		if running == nil {
+			return nil, nil, fmt.Errorf("%w: no running task to update", ErrConflict)
+		}
+		taskID = running.ID
+	}
+
+	input := TaskUpdateInput{}
+	if m.Input != nil {
+		input = *m.Input
+	}
+	task, err := c.UpdateTask(ctx, taskID, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stopped := running != nil && running.ID == taskID && input.Status != nil && *input.Status != TaskStatusDoing
+	if !stopped || event == nil {
+		if m.Description == nil {
+			return task, nil, nil
+		}
+		event, err = c.QueryTaskLastEvent(ctx, taskID)
+		if err != nil || event == nil {
+			return task, nil, err
+		}
+		event, err = c.UpdateEvent(ctx, event.ID, EventUpdateInput{Description: (*graphql.String)(m.Description)})
+		return task, event, err
+	}
+
+	if time.Time(m.At).Before(time.Time(event.StartAt)) {
+		return task, event, fmt.Errorf("%w: event(id=%d) started at %s, after the queued update at %s",
+			ErrConflict, event.ID, time.Time(event.StartAt).Format(time.RFC3339), time.Time(m.At).Format(time.RFC3339))
+	}
+	event, err = c.UpdateEvent(ctx, event.ID, EventUpdateInput{
+		EndAt:       &m.At,
+		Description: (*graphql.String)(m.Description),
+	})
+	return task, event, err
+}
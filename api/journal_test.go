@@ -0,0 +1,246 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/api/apitest"
+)
+
+// memJournal keeps the pending mutations in memory
+type memJournal struct {
+	pending []api.PendingMutation
+}
+
+func (j *memJournal) Load() ([]api.PendingMutation, error) {
+	return append([]api.PendingMutation(nil), j.pending...), nil
+}
+
+func (j *memJournal) Save(pending []api.PendingMutation) error {
+	j.pending = append([]api.PendingMutation(nil), pending...)
+	return nil
+}
+
+// offlineClient is a client of a backend that's down, queueing to the journal
+func offlineClient(t *testing.T, journal api.Journal) *api.Client {
+	t.Helper()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	return api.NewClient("token", api.WithEndpoint(down.URL), api.WithRetry(api.RetryPolicy{}), api.WithJournal(journal, nil))
+}
+
+func TestJournalReplay(t *testing.T) {
+	journal := &memJournal{}
+	var results []api.ReplayResult
+	client, _ := newTestClient(t, api.WithJournal(journal, func(r []api.ReplayResult) {
+		results = append(results, r...)
+	}))
+	ctx := context.Background()
+	task := createTasks(t, client, "a")[0]
+
+	offline := offlineClient(t, journal)
+	if _, _, err := offline.StartTask(ctx, task.ID); !errors.Is(err, api.ErrQueued) {
+		t.Fatalf("StartTask offline err = %v, want ErrQueued", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	paused := api.TaskStatusPaused
+	if _, err := offline.UpdateTask(ctx, task.ID, api.TaskUpdateInput{Status: &paused}); !errors.Is(err, api.ErrQueued) {
+		t.Fatalf("UpdateTask offline err = %v, want ErrQueued", err)
+	}
+	pending, err := client.Pending()
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("Pending = %+v, %v, want the start & the update", pending, err)
+	}
+
+	// any request of the live client replays first
+	if _, err = client.Me(ctx); err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("replayed %d mutations, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("replaying %s: %v", r.Kind, r.Err)
+		}
+	}
+	if pending, _ = client.Pending(); len(pending) != 0 {
+		t.Errorf("Pending after replay = %+v, want none", pending)
+	}
+
+	a, events, err := client.GetTaskEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTaskEvents: %v", err)
+	}
+	if a.Status != api.TaskStatusPaused {
+		t.Errorf("status = %s, want PAUSED", a.Status)
+	}
+	if len(events) != 1 || events[0].EndAt == nil {
+		t.Fatalf("events = %+v, want one ended", events)
+	}
+	// back-dated to the time queued
+	if d := time.Time(events[0].StartAt).Sub(time.Time(results[0].At)); d < -time.Second || d > time.Second {
+		t.Errorf("event started %s off the time queued", d)
+	}
+	if !time.Time(*events[0].EndAt).Equal(time.Time(results[1].At)) {
+		t.Errorf("event ended at %s, want the time queued %s", time.Time(*events[0].EndAt), time.Time(results[1].At))
+	}
+}
+
+func TestJournalReplayDropsConflicts(t *testing.T) {
+	journal := &memJournal{}
+	var results []api.ReplayResult
+	client, _ := newTestClient(t, api.WithJournal(journal, func(r []api.ReplayResult) {
+		results = append(results, r...)
+	}))
+	ctx := context.Background()
+	task := createTasks(t, client, "a")[0]
+
+	offline := offlineClient(t, journal)
+	done := api.TaskStatusDone
+	if _, err := offline.UpdateTask(ctx, 9999, api.TaskUpdateInput{Status: &done}); !errors.Is(err, api.ErrQueued) {
+		t.Fatalf("UpdateTask offline err = %v, want ErrQueued", err)
+	}
+	if _, _, err := offline.StartTask(ctx, task.ID); !errors.Is(err, api.ErrQueued) {
+		t.Fatalf("StartTask offline err = %v, want ErrQueued", err)
+	}
+
+	results, err := client.Replay(ctx)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("replayed %d mutations, want 2", len(results))
+	}
+	// the conflict is dropped, and the rest still replayed
+	if !errors.Is(results[0].Err, api.ErrNotFound) {
+		t.Errorf("update of a missing task err = %v, want ErrNotFound", results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("start err = %v, want none", results[1].Err)
+	}
+	if id := runningTaskID(t, client); id != task.ID {
+		t.Errorf("running task = %d, want %d", id, task.ID)
+	}
+	if pending, _ := client.Pending(); len(pending) != 0 {
+		t.Errorf("Pending after replay = %+v, want none", pending)
+	}
+}
+
+func TestJournalKeepsPendingWhileDown(t *testing.T) {
+	journal := &memJournal{}
+	offline := offlineClient(t, journal)
+	ctx := context.Background()
+
+	if _, _, err := offline.StartTask(ctx, 1); !errors.Is(err, api.ErrQueued) {
+		t.Fatalf("StartTask err = %v, want ErrQueued", err)
+	}
+	// the replay before it fails as well, the start stays pending
+	if _, err := offline.Me(ctx); !errors.Is(err, api.ErrNetwork) {
+		t.Fatalf("Me err = %v, want ErrNetwork", err)
+	}
+	if _, _, err := offline.StartTask(api.NoQueue(ctx), 1); !errors.Is(err, api.ErrNetwork) {
+		t.Errorf("StartTask with NoQueue err = %v, want ErrNetwork", err)
+	}
+	if pending, _ := offline.Pending(); len(pending) != 1 || pending[0].Kind != api.MutationTaskStart || pending[0].TaskID != 1 {
+		t.Errorf("Pending = %+v, want the start of task 1", pending)
+	}
+
+	// mutations after are queued behind
+	if _, _, err := offline.StartTask(ctx, 2); !errors.Is(err, api.ErrQueued) {
+		t.Fatalf("StartTask(2) err = %v, want ErrQueued", err)
+	}
+	if pending, _ := offline.Pending(); len(pending) != 2 || pending[1].TaskID != 2 {
+		t.Errorf("Pending = %+v, want the start of task 2 last", pending)
+	}
+}
+
+func TestJournalNotQueuedOnceSent(t *testing.T) {
+	// the connection drops once the request is read, it may have been applied
+	dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer dropping.Close()
+	journal := &memJournal{}
+	client := api.NewClient("token", api.WithEndpoint(dropping.URL), api.WithRetry(api.RetryPolicy{}), api.WithJournal(journal, nil))
+
+	_, _, err := client.StartTask(context.Background(), 1)
+	if !errors.Is(err, api.ErrNetwork) || errors.Is(err, api.ErrQueued) {
+		t.Fatalf("StartTask err = %v, want ErrNetwork, not queued", err)
+	}
+	if len(journal.pending) != 0 {
+		t.Errorf("pending = %+v, want none", journal.pending)
+	}
+}
+
+func TestJournalReplaySkippedWithoutSession(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	srv.AddUser("me@example.com", "password", "Me")
+	journal := &memJournal{pending: []api.PendingMutation{{Kind: api.MutationTaskStart, TaskID: 1, At: api.Time(time.Now())}}}
+	ctx := context.Background()
+
+	// an expired token, the replay would be rejected as well
+	expired := api.NewClient("expired", api.WithEndpoint(srv.Endpoint()), api.WithJournal(journal, nil))
+	_, err := expired.Me(ctx)
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.Kind != api.ErrUnauthenticated || apiErr.Path == "taskStart" {
+		t.Fatalf("Me err = %v, want ErrUnauthenticated of me, not of the replay", err)
+	}
+
+	for _, client := range []*api.Client{expired, api.NewClient("", api.WithEndpoint(srv.Endpoint()), api.WithJournal(journal, nil))} {
+		if _, err := client.Login(ctx, "me@example.com", "password"); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+	}
+	if len(journal.pending) != 1 {
+		t.Errorf("pending = %+v, want the start kept for the next session", journal.pending)
+	}
+}
+
+func TestJournalReplayFailureLetsRequestGo(t *testing.T) {
+	journal := &memJournal{}
+	client, srv := newTestClient(t, api.WithJournal(journal, nil))
+	task := createTasks(t, client, "a")[0]
+	journal.pending = []api.PendingMutation{{Kind: api.MutationTaskStart, TaskID: task.ID, At: api.Time(time.Now())}}
+
+	// the backend fails the start, but nothing else
+	var starts atomic.Int32
+	next := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("taskStart")) {
+			starts.Add(1)
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.Me(ctx); err != nil {
+			t.Fatalf("Me: %v", err)
+		}
+	}
+	if len(journal.pending) != 1 {
+		t.Errorf("pending = %+v, want the start kept", journal.pending)
+	}
+	// not retried by each request
+	if n := starts.Load(); n != 1 {
+		t.Errorf("replayed %d times, want once", n)
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	"github.com/todopeer/cli/util/gql"
+)
+
+// Operation is a GraphQL operation sent by a Client method
+type Operation struct {
+	Method string
+	Query  string
+	// Result is the type the response data is decoded into
+	Result reflect.Type
+}
+
+type captureKey struct{}
+
+type capture struct {
+	query  string
+	result any
+}
+
+var errCaptured = errors.New("captured")
+
+// captureTransport records the query of the request, instead of sending it
+type captureTransport struct{}
+
+func (captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if cp, ok := req.Context().Value(captureKey{}).(*capture); ok {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err = json.NewDecoder(bytes.NewReader(b)).Decode(&body); err != nil {
+			return nil, err
+		}
+		cp.query = body.Query
+	}
+	return nil, errCaptured
+}
+
+// Operations captures the operations the Client methods send, without reaching any backend
+func Operations() []Operation {
+	httpClient := &http.Client{Transport: captureTransport{}}
+	c := &Client{client: graphql.NewClient("http://capture.invalid/query", httpClient), httpClient: httpClient}
+
+	methods := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"GetEvent", func(ctx context.Context) error { _, err := c.GetEvent(ctx, 0); return err }},
+		{"QueryRunningEvent", func(ctx context.Context) error { _, err := c.QueryRunningEvent(ctx); return err }},
+		{"QueryLatestEvents", func(ctx context.Context) error { _, err := c.QueryLatestEvents(ctx); return err }},
+		{"QueryEventsPage", func(ctx context.Context) error { _, err := c.QueryEventsPage(ctx, time.Now(), 1, 1, 1); return err }},
+		{"DeleteEvent", func(ctx context.Context) error { _, err := c.DeleteEvent(ctx, 0); return err }},
+		{"UpdateEvent", func(ctx context.Context) error { _, err := c.UpdateEvent(ctx, 0, EventUpdateInput{}); return err }},
+		{"QueryTaskLastEvent", func(ctx context.Context) error { _, err := c.QueryTaskLastEvent(ctx, 0); return err }},
+		{"QueryTasks", func(ctx context.Context) error { _, err := c.QueryTasks(ctx, QueryTaskInput{}); return err }},
+		{"CreateTask", func(ctx context.Context) error { _, err := c.CreateTask(ctx, TaskCreateInput{}); return err }},
+		{"DeleteTask", func(ctx context.Context) error { _, err := c.DeleteTask(ctx, 0); return err }},
+		{"StartTask", func(ctx context.Context) error { _, _, err := c.StartTask(ctx, 0); return err }},
+		{"UpdateTask", func(ctx context.Context) error { _, err := c.UpdateTask(ctx, 0, TaskUpdateInput{}); return err }},
+		{"UndeleteTask", func(ctx context.Context) error { _, err := c.UndeleteTask(ctx, 0); return err }},
+		{"GetTaskEvents", func(ctx context.Context) error { _, _, err := c.GetTaskEvents(ctx, 0); return err }},
+		{"Login", func(ctx context.Context) error { _, err := c.Login(ctx, "", ""); return err }},
+		{"MeWithTaskEvent", func(ctx context.Context) error { _, _, _, err := c.MeWithTaskEvent(ctx); return err }},
+		{"Me", func(ctx context.Context) error { _, err := c.Me(ctx); return err }},
+		{"Logout", func(ctx context.Context) error { return c.Logout(ctx) }},
+	}
+
+	res := make([]Operation, 0, len(methods))
+	for _, m := range methods {
+		cp := &capture{}
+		m.call(context.WithValue(context.Background(), captureKey{}, cp))
+		res = append(res, Operation{Method: m.name, Query: cp.query, Result: reflect.TypeOf(cp.result).Elem()})
+	}
+	return res
+}
+
+// SchemaIssue is an incompatibility of an operation the client sends with the schema
+type SchemaIssue struct {
+	Method string `json:"method"`
+	gql.Issue
+}
+
+// CheckSchema checks the operations of all Client methods against the schema: the documents sent, and the Go types
+// the responses are decoded into
+func CheckSchema(schema *gql.Schema) []SchemaIssue {
+	res := []SchemaIssue{}
+	for _, op := range Operations() {
+		for _, issue := range CheckOperation(schema, op) {
+			res = append(res, SchemaIssue{Method: op.Method, Issue: issue})
+		}
+	}
+	return res
+}
+
+// CheckOperation checks the operation against the schema
+func CheckOperation(schema *gql.Schema, op Operation) []gql.Issue {
+	doc, err := gql.Parse(op.Query)
+	if err != nil {
+		return []gql.Issue{{Severity: gql.SeverityError, Message: "client sends an invalid document: " + err.Error()}}
+	}
+	operation, err := doc.Operation("")
+	if err != nil {
+		return []gql.Issue{{Severity: gql.SeverityError, Message: err.Error()}}
+	}
+
+	issues := gql.Check(schema, doc, operation)
+	if root := schema.RootType(operation.Type); root != nil {
+		k := &goChecker{schema: schema, doc: doc}
+		k.object(op.Result, root, operation.SelectionSet, "")
+		issues = append(issues, k.issues...)
+	}
+	return issues
+}
+
+// goChecker checks the Go types responses are decoded into, against the types in the schema
+type goChecker struct {
+	schema *gql.Schema
+	doc    *gql.Document
+	issues []gql.Issue
+}
+
+func (k *goChecker) mismatch(path, format string, args ...any) {
+	k.issues = append(k.issues, gql.Issue{
+		Severity: gql.SeverityError,
+		Path:     path,
+		Message:  "type mismatch: " + fmt.Sprintf(format, args...),
+	})
+}
+
+func (k *goChecker) object(t reflect.Type, def *gql.TypeDef, sels []gql.Selection, path string) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *gql.Field:
+			fieldDef := def.Field(sel.Name)
+			goField, found := goFieldFor(t, sel.ResponseKey())
+			if fieldDef == nil || !found {
+				// unknown fields are reported by gql.Check
+				continue
+			}
+			fieldPath := sel.ResponseKey()
+			if path != "" {
+				fieldPath = path + "." + fieldPath
+			}
+			k.value(goField.Type, fieldDef.Type, sel.SelectionSet, fieldPath)
+		case *gql.InlineFragment:
+			cond := def
+			if typeDef := k.schema.Types[sel.TypeCondition]; typeDef != nil {
+				cond = typeDef
+			}
+			k.object(t, cond, sel.SelectionSet, path)
+		case *gql.FragmentSpread:
+			if f := k.doc.Fragments[sel.Name]; f != nil && k.schema.Types[f.TypeCondition] != nil {
+				k.object(t, k.schema.Types[f.TypeCondition], f.SelectionSet, path)
+			}
+		}
+	}
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func (k *goChecker) value(t reflect.Type, ref *gql.TypeRef, sels []gql.Selection, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	isList := t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+	if (ref.Elem != nil) != isList {
+		k.mismatch(path, "schema has %s, decoded into %s", ref, t)
+		return
+	}
+	if isList {
+		k.value(t.Elem(), ref.Elem, sels, path)
+		return
+	}
+
+	def := k.schema.Types[ref.Name]
+	if def == nil {
+		return
+	}
+	switch def.Kind {
+	case gql.KindScalar, gql.KindEnum:
+		if !goAccepts(t, def) {
+			k.mismatch(path, "schema has %s, decoded into %s", ref, t)
+		}
+	default:
+		if t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+			k.mismatch(path, "schema has object %s, decoded into %s", ref, t)
+			return
+		}
+		k.object(t, def, sels, path)
+	}
+}
+
+// goAccepts tells whether values of the scalar or enum decode into t
+func goAccepts(t reflect.Type, def *gql.TypeDef) bool {
+	if t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return def.Kind == gql.KindEnum || def.Name == "String" || def.Name == "ID" || !isBuiltinScalar(def.Name)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return def.Name == "Int" || def.Name == "ID" || !isBuiltinScalar(def.Name) && def.Kind == gql.KindScalar
+	case reflect.Float32, reflect.Float64:
+		return def.Name == "Float" || def.Name == "Int" || !isBuiltinScalar(def.Name) && def.Kind == gql.KindScalar
+	case reflect.Bool:
+		return def.Name == "Boolean" || !isBuiltinScalar(def.Name) && def.Kind == gql.KindScalar
+	}
+	return false
+}
+
+func isBuiltinScalar(name string) bool {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
+// goFieldFor finds the struct field a response key is decoded into, the way the graphql client does:
+// by the graphql tag, or case-insensitively by field name, looking into embedded structs & fragments too
+func goFieldFor(t reflect.Type, key string) (reflect.StructField, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("graphql")
+		tag = strings.TrimSpace(tag)
+
+		if tagged && !strings.HasPrefix(tag, "...") {
+			name := tag
+			if i := strings.Index(name, "("); i != -1 {
+				name = name[:i]
+			}
+			if i := strings.Index(name, ":"); i != -1 {
+				name = name[:i]
+			}
+			if strings.TrimSpace(name) == key {
+				return f, true
+			}
+			continue
+		}
+		if !tagged && strings.EqualFold(f.Name, key) {
+			return f, true
+		}
+		if f.Anonymous || strings.HasPrefix(tag, "...") {
+			if inner, found := goFieldFor(f.Type, key); found {
+				return inner, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
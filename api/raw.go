@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/todopeer/cli/util/gql"
+)
+
+// RawResponse is a GraphQL response, kept undecoded
+type RawResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     json.RawMessage `json:"errors,omitempty"`
+	Extensions json.RawMessage `json:"extensions,omitempty"`
+}
+
+// Raw sends the query document as is, for fields the typed methods don't expose. Mutations in it clear the cache, and
+// are not retried. The response is returned whenever the backend answers with one, together with the error it carries
+func (c *Client) Raw(ctx context.Context, query string, variables map[string]any, operationName string) (*RawResponse, error) {
+	body, err := json.Marshal(struct {
+		Query         string         `json:"query"`
+		Variables     map[string]any `json:"variables,omitempty"`
+		OperationName string         `json:"operationName,omitempty"`
+	}{query, variables, operationName})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *RawResponse
+	err = c.send(ctx, operationOf(query, operationName), func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		var out RawResponse
+		if err = json.NewDecoder(res.Body).Decode(&out); err != nil {
+			return fmt.Errorf("status %d, undecodable body: %w", res.StatusCode, err)
+		}
+		resp = &out
+
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %d", res.StatusCode)
+		}
+		if len(out.Errors) > 0 && string(out.Errors) != "null" {
+			return errors.New("response with errors")
+		}
+		return nil
+	})
+
+	if logFunc != nil {
+		logFunc("Raw(%q, %s): %v", redactQuery(query), redactJSON(variables), err)
+	}
+	return resp, err
+}
+
+// operationOf tells the operation to run in the document. Documents failing to parse are taken as mutations,
+// so they're never retried; the backend reports what's wrong
+func operationOf(query, operationName string) operation {
+	doc, err := gql.Parse(query)
+	if err != nil {
+		return opMutate
+	}
+	op, err := doc.Operation(operationName)
+	if err != nil || op.Type == "mutation" {
+		return opMutate
+	}
+	return opQuery
+}
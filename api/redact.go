@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// isSecretKey tells whether values under the key are secrets, to keep them out of the debug log
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range []string{"password", "passphrase", "token", "secret", "authorization"} {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON encodes v as JSON for the debug log, with the values of secret keys redacted
+func redactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc any
+	if err = dec.Decode(&doc); err != nil {
+		return string(b)
+	}
+	b, _ = json.Marshal(redactValue(doc))
+	return string(b)
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSecretKey(key) && value != nil {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
+
+// secretArgument matches string literals given to secret arguments within query documents
+var secretArgument = regexp.MustCompile(`(?i)(\w*(?:password|passphrase|token|secret)\w*\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// redactQuery redacts the secrets inlined in the query document
+func redactQuery(query string) string {
+	return secretArgument.ReplaceAllString(query, `$1"`+redacted+`"`)
+}
+
+// redactHeader copies the header for the debug log, with the credentials redacted
+func redactHeader(h http.Header) http.Header {
+	res := h.Clone()
+	for key := range res {
+		if isSecretKey(key) || strings.EqualFold(key, "Cookie") {
+			res[key] = []string{redacted}
+		}
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"secret keys, any case", map[string]any{"email": "me@example.com", "Password": "hunter2", "newToken": "abc"},
+			`{"Password":"[REDACTED]","email":"me@example.com","newToken":"[REDACTED]"}`},
+		{"nested", map[string]any{"input": map[string]any{"passphrase": "p", "name": "n"}, "list": []any{map[string]any{"secret": 1}}},
+			`{"input":{"name":"n","passphrase":"[REDACTED]"},"list":[{"secret":"[REDACTED]"}]}`},
+		{"null secrets kept, telling they're unset", map[string]any{"token": nil},
+			`{"token":null}`},
+		{"structs by their JSON names", struct {
+			Token string `json:"token"`
+			ID    int64  `json:"id"`
+		}{"abc", 12345678901234567},
+			`{"id":12345678901234567,"token":"[REDACTED]"}`},
+		{"no secrets", []int{1, 2}, `[1,2]`},
+	} {
+		if got := redactJSON(tc.in); got != tc.want {
+			t.Errorf("%s: redactJSON = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`mutation { login(email: "me@example.com", password: "hunter2") { token } }`,
+			`mutation { login(email: "me@example.com", password: "[REDACTED]") { token } }`},
+		{`{ a(newPassword:"x \" y", apiToken : "t") }`,
+			`{ a(newPassword:"[REDACTED]", apiToken : "[REDACTED]") }`},
+		// variables carry no secrets inline
+		{`mutation($password: String!) { login(password: $password) { token } }`,
+			`mutation($password: String!) { login(password: $password) { token } }`},
+		{`{ tasks(name: "password") { id } }`, `{ tasks(name: "password") { id } }`},
+	} {
+		if got := redactQuery(tc.in); got != tc.want {
+			t.Errorf("redactQuery(%s) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"session=abc"},
+		"X-Api-Token":   {"abc"},
+		"Content-Type":  {"application/json"},
+	}
+	got := redactHeader(h)
+	for key, want := range map[string]string{
+		"Authorization": redacted,
+		"Cookie":        redacted,
+		"X-Api-Token":   redacted,
+		"Content-Type":  "application/json",
+	} {
+		if got.Get(key) != want {
+			t.Errorf("%s = %q, want %q", key, got.Get(key), want)
+		}
+	}
+	if h.Get("Authorization") != "Bearer abc" {
+		t.Errorf("the header given got redacted: %v", h)
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy decides how many times, and how long to wait, before retrying a failed request.
+// Only queries & mutations marked by RetrySafe are retried, unless the request never got sent
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  200 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
+}
+
+// WithRetry sets the retry policy of the client. Zero MaxRetries disables retrying
+func WithRetry(policy RetryPolicy) ClientOptionFunc {
+	return func(o *clientOption) {
+		o.retry = policy
+	}
+}
+
+type retrySafeKey struct{}
+
+// RetrySafe marks the mutations made with ctx as safe to retry, as they're idempotent
+func RetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+func isRetrySafe(ctx context.Context) bool {
+	safe, _ := ctx.Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+// delay gives the backoff before the n-th retry (starting from 0): exponential, with jitter in the upper half
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << n
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// roundTripWithRetry sends req, retrying per policy. The body is re-created from req.GetBody for each attempt
+func (t *transport) roundTripWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := isRetrySafe(ctx)
+	cl := callFrom(ctx)
+	if cl != nil && cl.op == opQuery {
+		idempotent = true
+	}
+
+	// the body can only be sent once, without a way to re-create it
+	maxRetries := t.retry.MaxRetries
+	if req.Body != nil && req.GetBody == nil {
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		var sent atomic.Bool
+		trace := &httptrace.ClientTrace{
+			WroteHeaders: func() {
+				sent.Store(true)
+				if cl != nil {
+					cl.sent.Store(true)
+				}
+			},
+		}
+		resp, err := http.DefaultTransport.RoundTrip(r.WithContext(httptrace.WithClientTrace(ctx, trace)))
+
+		if attempt >= maxRetries || !shouldRetry(resp, err, idempotent || !sent.Load()) || ctx.Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		delay := t.retry.delay(attempt)
+		if logFunc != nil {
+			logFunc("retry #%d in %s: status=%d err=%v", attempt+1, delay, statusOf(resp), err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry retries transport errors & temporary unavailability. GraphQL errors are never retried
+func shouldRetry(resp *http.Response, err error, idempotent bool) bool {
+	if !idempotent {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for _, tc := range []struct {
+		n    int
+		want time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		// shifted out of range
+		{70, time.Second},
+	} {
+		for i := 0; i < 100; i++ {
+			if d := p.delay(tc.n); d < tc.want/2 || d > tc.want {
+				t.Fatalf("delay(%d) = %s, want within %s & %s", tc.n, d, tc.want/2, tc.want)
+			}
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		status     int
+		err        error
+		idempotent bool
+		want       bool
+	}{
+		{"transport error", 0, errors.New("connection refused"), true, true},
+		{"transport error, not idempotent", 0, errors.New("connection reset"), false, false},
+		{"too many requests", http.StatusTooManyRequests, nil, true, true},
+		{"bad gateway", http.StatusBadGateway, nil, true, true},
+		{"unavailable", http.StatusServiceUnavailable, nil, true, true},
+		{"gateway timeout", http.StatusGatewayTimeout, nil, true, true},
+		{"unavailable, not idempotent", http.StatusServiceUnavailable, nil, false, false},
+		{"ok", http.StatusOK, nil, true, false},
+		{"internal error", http.StatusInternalServerError, nil, true, false},
+		{"unauthorized", http.StatusUnauthorized, nil, true, false},
+	} {
+		var resp *http.Response
+		if tc.err == nil {
+			resp = &http.Response{StatusCode: tc.status}
+		}
+		if got := shouldRetry(resp, tc.err, tc.idempotent); got != tc.want {
+			t.Errorf("%s: shouldRetry = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRoundTripWithRetry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context
+		failures int32
+		status   int
+		requests int32
+	}{
+		{"retry safe, recovering", RetrySafe(context.Background()), 2, http.StatusOK, 3},
+		{"retry safe, giving up", RetrySafe(context.Background()), 10, http.StatusServiceUnavailable, 4},
+		{"mutation sent, not retried", context.Background(), 2, http.StatusServiceUnavailable, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// the body is sent again on each attempt
+				if body, _ := io.ReadAll(r.Body); string(body) != "{}" {
+					t.Errorf("body = %q, want {}", body)
+				}
+				if requests.Add(1) <= tc.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			req, err := http.NewRequestWithContext(tc.ctx, http.MethodPost, srv.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&transport{retry: policy}).roundTripWithRetry(req)
+			if err != nil {
+				t.Fatalf("roundTripWithRetry: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tc.status)
+			}
+			if n := requests.Load(); n != tc.requests {
+				t.Errorf("sent %d requests, want %d", n, tc.requests)
+			}
+		})
+	}
+}
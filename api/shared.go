@@ -2,14 +2,18 @@ package api
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/shurcooL/graphql"
 )
 
-const gqlAPI = "https://api.todopeer.com/query"
+// DefaultEndpoint is the GraphQL endpoint used when no other endpoint is configured
+const DefaultEndpoint = "https://api.todopeer.com/query"
 
 type LogFunc func(string, ...interface{})
 
@@ -21,49 +25,185 @@ func SetLogger(logger LogFunc) {
 
 type transport struct {
 	token string
+	retry RetryPolicy
 }
 
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
-	return http.DefaultTransport.RoundTrip(req)
+	if t.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
+	}
+	if logFunc != nil {
+		logFunc("%s %s, header: %v", req.Method, req.URL, redactHeader(req.Header))
+	}
+
+	resp, err := t.roundTripWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c := callFrom(req.Context()); c != nil {
+		if err = c.record(resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
-func newClientWithToken(token string) *graphql.Client {
-	return graphql.NewClient(gqlAPI, &http.Client{
-		Transport: &transport{token: token},
-	})
+type clientOption struct {
+	endpoint string
+	timeout  time.Duration
+	retry    RetryPolicy
+	journal  Journal
+	onReplay func([]ReplayResult)
+
+	cache     Cache
+	cacheMode CacheMode
+
+	lookbackDays int
+}
+
+type ClientOptionFunc func(*clientOption)
+
+// WithEndpoint points the client to the given GraphQL endpoint. Empty endpoint keeps the default
+func WithEndpoint(endpoint string) ClientOptionFunc {
+	return func(o *clientOption) {
+		if endpoint != "" {
+			o.endpoint = endpoint
+		}
+	}
+}
+
+// DefaultLookbackDays is how far back QueryLatestEvents looks, by default
+const DefaultLookbackDays = 2
+
+// WithLookback sets how many days back QueryLatestEvents looks for events
+func WithLookback(days int) ClientOptionFunc {
+	return func(o *clientOption) {
+		if days > 0 {
+			o.lookbackDays = days
+		}
+	}
+}
+
+// WithTimeout limits the time of each request. Zero means no limit
+func WithTimeout(timeout time.Duration) ClientOptionFunc {
+	return func(o *clientOption) {
+		o.timeout = timeout
+	}
 }
 
 type Client struct {
 	client *graphql.Client
-	ctx    context.Context
+	// httpClient & endpoint are for raw requests, see Raw
+	httpClient *http.Client
+	endpoint   string
+	timeout    time.Duration
+
+	journal  Journal
+	onReplay func([]ReplayResult)
+	// noSession is set without a token, or once it's rejected; replayHeld once a replay failed for other reasons.
+	// Either skips replaying before the requests, see replayPending
+	noSession  atomic.Bool
+	replayHeld atomic.Bool
+	// replayMu serializes the rewrites of the journal: by Replay, and by Queue for mutations made concurrently
+	replayMu sync.Mutex
+
+	cache     Cache
+	cacheMode CacheMode
+	// cacheGen is bumped on mutations, so refreshes racing with them aren't cached
+	cacheGen   atomic.Int64
+	refreshing sync.WaitGroup
+
+	lookbackDays int
 }
 
-func NewClient(token string) *Client {
-	return &Client{client: newClientWithToken(token), ctx: context.Background()}
+func NewClient(token string, options ...ClientOptionFunc) *Client {
+	cfg := clientOption{endpoint: DefaultEndpoint, retry: DefaultRetryPolicy, lookbackDays: DefaultLookbackDays}
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	httpClient := &http.Client{
+		Transport: &transport{token: token, retry: cfg.retry},
+	}
+	c := &Client{
+		client:     graphql.NewClient(cfg.endpoint, httpClient),
+		httpClient: httpClient,
+		endpoint:   cfg.endpoint,
+		timeout:    cfg.timeout,
+		journal:    cfg.journal,
+		onReplay:   cfg.onReplay,
+		cache:      cfg.cache,
+		cacheMode:  cfg.cacheMode,
+
+		lookbackDays: cfg.lookbackDays,
+	}
+	c.noSession.Store(token == "")
+	return c
 }
 
-func (c *Client) Mutate(m any, variables map[string]any) error {
-	err := c.client.Mutate(c.ctx, m, variables)
+type operation int
 
-	if logFunc != nil {
-		cmdS, _ := json.Marshal(m)
-		varS, _ := json.Marshal(variables)
+const (
+	opQuery operation = iota
+	opMutate
+)
 
-		logFunc("Mutate(%s, %s): %v", string(cmdS), string(varS), err)
+// do runs the operation, turning failures into *Error
+func (c *Client) do(ctx context.Context, op operation, m any, variables map[string]any) error {
+	if cp, ok := ctx.Value(captureKey{}).(*capture); ok {
+		cp.result = m
 	}
+	return c.send(ctx, op, func(ctx context.Context) error {
+		if op == opMutate {
+			return c.client.Mutate(ctx, m, variables)
+		}
+		return c.client.Query(ctx, m, variables)
+	})
+}
 
+// send makes the request with the context for op: pending mutations replayed before, timeout & error capturing.
+// Rejected tokens end the session, see replayPending
+func (c *Client) send(ctx context.Context, op operation, request func(context.Context) error) error {
+	if err := c.replayPending(ctx, op); err != nil {
+		return err
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	cl := &call{op: op}
+	ctx = withCall(ctx, cl)
+
+	if op == opMutate {
+		// cleared even on errors, as the mutation might have been applied
+		defer c.invalidateCache()
+	}
+	err := cl.toError(request(ctx))
+	if errors.Is(err, ErrUnauthenticated) {
+		c.noSession.Store(true)
+	}
 	return err
 }
 
-func (c *Client) Query(m any, variables map[string]any) error {
-	err := c.client.Query(c.ctx, m, variables)
+func (c *Client) Mutate(ctx context.Context, m any, variables map[string]any) error {
+	err := c.do(ctx, opMutate, m, variables)
 
 	if logFunc != nil {
-		cmdS, _ := json.Marshal(m)
-		varS, _ := json.Marshal(variables)
+		logFunc("Mutate(%s, %s): %v", redactJSON(m), redactJSON(variables), err)
+	}
 
-		logFunc("Query(%s, %s): %v", string(cmdS), string(varS), err)
+	return err
+}
+
+func (c *Client) Query(ctx context.Context, m any, variables map[string]any) error {
+	err := c.do(ctx, opQuery, m, variables)
+
+	if logFunc != nil {
+		logFunc("Query(%s, %s): %v", redactJSON(m), redactJSON(variables), err)
 	}
 
 	return err
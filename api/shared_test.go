@@ -0,0 +1,76 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shurcooL/graphql"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/api/apitest"
+)
+
+// newTestClient starts a fake backend with a user, and returns a client logged in as the user
+func newTestClient(t *testing.T, options ...api.ClientOptionFunc) (*api.Client, *apitest.Server) {
+	t.Helper()
+	srv := apitest.NewServer()
+	t.Cleanup(srv.Close)
+	return clientOf(srv, options...), srv
+}
+
+// clientOf adds a user to the server, and returns a client logged in as the user
+func clientOf(srv *apitest.Server, options ...api.ClientOptionFunc) *api.Client {
+	token := srv.AddUser("me@example.com", "password", "Me")
+	options = append([]api.ClientOptionFunc{api.WithEndpoint(srv.Endpoint())}, options...)
+	return api.NewClient(token, options...)
+}
+
+// createTasks creates the tasks by name, failing the test on errors
+func createTasks(t *testing.T, client *api.Client, names ...string) []*api.Task {
+	t.Helper()
+	tasks := make([]*api.Task, len(names))
+	for i, name := range names {
+		task, err := client.CreateTask(context.Background(), api.TaskCreateInput{Name: graphql.String(name)})
+		if err != nil {
+			t.Fatalf("CreateTask(%s): %v", name, err)
+		}
+		tasks[i] = task
+	}
+	return tasks
+}
+
+// runningTaskID is the ID of the running task, 0 if none
+func runningTaskID(t *testing.T, client *api.Client) api.ID {
+	t.Helper()
+	user, err := client.Me(context.Background())
+	if err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	if user.RunningTaskID == nil {
+		return 0
+	}
+	return *user.RunningTaskID
+}
+
+func TestClientLogin(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	srv.AddUser("me@example.com", "password", "Me")
+	client := api.NewClient("", api.WithEndpoint(srv.Endpoint()))
+	ctx := context.Background()
+
+	payload, err := client.Login(ctx, "me@example.com", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if payload.Token == "" || payload.User.Email != "me@example.com" {
+		t.Errorf("Login = %+v, want a token for me@example.com", payload)
+	}
+
+	user, err := api.NewClient(string(payload.Token), api.WithEndpoint(srv.Endpoint())).Me(ctx)
+	if err != nil {
+		t.Fatalf("Me with the token of Login: %v", err)
+	}
+	if user.Name != "Me" {
+		t.Errorf("Me().Name = %q, want Me", user.Name)
+	}
+}
@@ -1,7 +1,7 @@
 package api
 
 import (
-	"fmt"
+	"context"
 	"time"
 
 	"github.com/shurcooL/graphql"
@@ -17,17 +17,23 @@ var (
 )
 
 type Task struct {
-	ID          ID
-	Name        graphql.String
-	Description graphql.String
-	Status      TaskStatus
-	CreatedAt   Time
-	UpdatedAt   Time
-	DueDate     *Time
+	ID          ID             `json:"id"`
+	Name        graphql.String `json:"name"`
+	Description graphql.String `json:"description"`
+	Status      TaskStatus     `json:"status"`
+	CreatedAt   Time           `json:"createdAt"`
+	UpdatedAt   Time           `json:"updatedAt"`
+	DueDate     *Date          `json:"dueDate"`
 }
 
-func (t *Task) Output() {
-	fmt.Printf("%d\t%s\t%s\t%s\n", t.ID, t.Status, t.Name, t.DueDate.DateTime())
+// DueDay is the due date like 2006-01-02, empty if there's none
+func (t *Task) DueDay() string {
+	return t.DueDate.String()
+}
+
+// IsOverdue tells whether the task is past its due date, and not done
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.DueDate != nil && t.Status != TaskStatusDone && time.Time(*t.DueDate).Before(now)
 }
 
 type TaskUpdateInput struct {
@@ -35,13 +41,53 @@ type TaskUpdateInput struct {
 	Description *graphql.String `json:"description"`
 	Status      *TaskStatus     `json:"status"`
 	DueDate     *graphql.String `json:"dueDate"`
+	// ParentID moves the task under another one; 0 makes it top-level
+	ParentID *ID `json:"parentID,omitempty"`
 }
 
+type TaskSort string
+
+var (
+	TaskSortDueDate   TaskSort = "DUE_DATE"
+	TaskSortCreatedAt TaskSort = "CREATED_AT"
+	TaskSortUpdatedAt TaskSort = "UPDATED_AT"
+	TaskSortName      TaskSort = "NAME"
+)
+
+// QueryTaskInput selects the tasks to query. Zero Limit is no limit. Tasks are ordered by Sort, or ID if it's empty,
+// for paging with Offset
 type QueryTaskInput struct {
 	Status []TaskStatus `json:"status"`
+	TaskFilter
+	Sort    TaskSort `json:"sort,omitempty"`
+	Reverse bool     `json:"reverse,omitempty"`
+	Limit   int      `json:"limit,omitempty"`
+	Offset  int      `json:"offset,omitempty"`
 }
 
-func (c *Client) QueryTaskLastEvent(taskID ID) (*Event, error) {
+// TaskFilter narrows down the tasks queried. Zero fields don't filter. Before & After bounds are exclusive
+type TaskFilter struct {
+	// Search is a substring of the name or description, matched case-insensitively
+	Search string `json:"search,omitempty"`
+	// Regex matches the name or description
+	Regex string `json:"regex,omitempty"`
+
+	DueBefore     *Time `json:"dueBefore,omitempty"`
+	DueAfter      *Time `json:"dueAfter,omitempty"`
+	CreatedBefore *Time `json:"createdBefore,omitempty"`
+	CreatedAfter  *Time `json:"createdAfter,omitempty"`
+	UpdatedBefore *Time `json:"updatedBefore,omitempty"`
+	UpdatedAfter  *Time `json:"updatedAfter,omitempty"`
+	// Overdue keeps the tasks past their due date, and not done
+	Overdue bool `json:"overdue,omitempty"`
+	// EventsBefore & EventsAfter keep the tasks with events started in the range
+	EventsBefore *Time `json:"eventsBefore,omitempty"`
+	EventsAfter  *Time `json:"eventsAfter,omitempty"`
+	// ParentID keeps the subtasks of the task
+	ParentID *ID `json:"parentID,omitempty"`
+}
+
+func (c *Client) QueryTaskLastEvent(ctx context.Context, taskID ID) (*Event, error) {
 	var query struct {
 		Task struct {
 			Events []Event `graphql:"events(input:{limit:1})"`
@@ -52,7 +98,7 @@ func (c *Client) QueryTaskLastEvent(taskID ID) (*Event, error) {
 		"id": taskID,
 	}
 
-	err := c.Query(&query, variables)
+	err := c.Query(ctx, &query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +110,7 @@ func (c *Client) QueryTaskLastEvent(taskID ID) (*Event, error) {
 	return &query.Task.Events[0], nil
 }
 
-func (c *Client) QueryTasks(input QueryTaskInput) ([]*Task, error) {
+func (c *Client) QueryTasks(ctx context.Context, input QueryTaskInput) ([]*Task, error) {
 	query := struct {
 		Tasks []*Task `graphql:"tasks(input:$input)"`
 	}{}
@@ -72,7 +118,7 @@ func (c *Client) QueryTasks(input QueryTaskInput) ([]*Task, error) {
 		"input": input,
 	}
 
-	err := c.Query(&query, variables)
+	err := c.cachedQuery(ctx, &query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -80,13 +126,42 @@ func (c *Client) QueryTasks(input QueryTaskInput) ([]*Task, error) {
 	return query.Tasks, nil
 }
 
+// QueryTaskParents maps the tasks of the statuses to their parents; top-level tasks aren't in it. It's queried apart
+// from the tasks, only for trees & subtasks, as older backends don't have parents
+func (c *Client) QueryTaskParents(ctx context.Context, status []TaskStatus) (map[ID]ID, error) {
+	query := struct {
+		Tasks []struct {
+			ID       ID  `json:"id"`
+			ParentID *ID `json:"parentID" graphql:"parentID"`
+		} `graphql:"tasks(input:$input)"`
+	}{}
+	variables := map[string]interface{}{
+		"input": QueryTaskInput{Status: status},
+	}
+
+	err := c.cachedQuery(ctx, &query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[ID]ID)
+	for _, t := range query.Tasks {
+		if t.ParentID != nil {
+			parents[t.ID] = *t.ParentID
+		}
+	}
+	return parents, nil
+}
+
 type TaskCreateInput struct {
 	Name        graphql.String  `json:"name"`
 	Description *graphql.String `json:"description"`
 	DueDate     *graphql.String `json:"dueDate"`
+	// ParentID creates the task as a subtask
+	ParentID *ID `json:"parentID,omitempty"`
 }
 
-func (c *Client) CreateTask(input TaskCreateInput) (*Task, error) {
+func (c *Client) CreateTask(ctx context.Context, input TaskCreateInput) (*Task, error) {
 	var mutation struct {
 		TaskCreate Task `graphql:"taskCreate(input: $input)"`
 	}
@@ -95,7 +170,7 @@ func (c *Client) CreateTask(input TaskCreateInput) (*Task, error) {
 		"input": input,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	err := c.Mutate(ctx, &mutation, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -103,11 +178,21 @@ func (c *Client) CreateTask(input TaskCreateInput) (*Task, error) {
 	return &mutation.TaskCreate, nil
 }
 
+// QuerySubtasks queries the direct subtasks of the task, of all statuses
+func (c *Client) QuerySubtasks(ctx context.Context, taskID ID) ([]*Task, error) {
+	var res []*Task
+	it := c.IterateTasks(QueryTaskInput{TaskFilter: TaskFilter{ParentID: &taskID}}, DefaultPageSize)
+	for it.Next(ctx) {
+		res = append(res, it.Task())
+	}
+	return res, it.Err()
+}
+
 type TaskDeleteInput struct {
 	TaskID graphql.String
 }
 
-func (c *Client) DeleteTask(taskID ID) (*Task, error) {
+func (c *Client) DeleteTask(ctx context.Context, taskID ID) (*Task, error) {
 	var mutation struct {
 		TaskDelete Task `graphql:"taskDelete(id: $id)"`
 	}
@@ -116,7 +201,7 @@ func (c *Client) DeleteTask(taskID ID) (*Task, error) {
 		"id": taskID,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	err := c.Mutate(ctx, &mutation, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +228,7 @@ func StartTaskWithDescription(description string) StartTaskOptionFunc {
 	}
 }
 
-func (c *Client) StartTask(taskID ID, options ...StartTaskOptionFunc) (*Task, *Event, error) {
+func (c *Client) StartTask(ctx context.Context, taskID ID, options ...StartTaskOptionFunc) (*Task, *Event, error) {
 	cfg := startTaskOption{}
 	for _, option := range options {
 		option(&cfg)
@@ -156,22 +241,25 @@ func (c *Client) StartTask(taskID ID, options ...StartTaskOptionFunc) (*Task, *E
 		} `graphql:"taskStart(id: $id, input: {startAt: $startAt, description: $description})"`
 	}
 
+	startAt := time.Now().Add(-cfg.offset)
 	variables := map[string]interface{}{
 		"id":          taskID,
 		"description": (*graphql.String)(cfg.desc),
-		"startAt":     time.Now().Add(-cfg.offset),
+		"startAt":     startAt,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	err := c.Mutate(ctx, &mutation, variables)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, c.queueOnNetworkError(ctx, err, PendingMutation{
+			Kind: MutationTaskStart, TaskID: taskID, At: Time(startAt), Description: cfg.desc,
+		})
 	}
 
 	resp := mutation.TaskStart
 	return &resp.Task, resp.Event, nil
 }
 
-func (c *Client) UpdateTask(taskID ID, input TaskUpdateInput) (*Task, error) {
+func (c *Client) UpdateTask(ctx context.Context, taskID ID, input TaskUpdateInput) (*Task, error) {
 	var mutation struct {
 		TaskUpdate Task `graphql:"taskUpdate(id:$id, input: $input)"`
 	}
@@ -181,15 +269,17 @@ func (c *Client) UpdateTask(taskID ID, input TaskUpdateInput) (*Task, error) {
 		"input": input,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	err := c.Mutate(ctx, &mutation, variables)
 	if err != nil {
-		return nil, err
+		return nil, c.queueOnNetworkError(ctx, err, PendingMutation{
+			Kind: MutationTaskUpdate, TaskID: taskID, At: Time(time.Now()), Input: &input,
+		})
 	}
 
 	return &mutation.TaskUpdate, nil
 }
 
-func (c *Client) UndeleteTask(taskID ID) (*Task, error) {
+func (c *Client) UndeleteTask(ctx context.Context, taskID ID) (*Task, error) {
 	var mutation struct {
 		TaskUndelete Task `graphql:"taskUndelete(id: $id)"`
 	}
@@ -198,7 +288,7 @@ func (c *Client) UndeleteTask(taskID ID) (*Task, error) {
 		"id": taskID,
 	}
 
-	err := c.Mutate(&mutation, variables)
+	err := c.Mutate(ctx, &mutation, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +296,7 @@ func (c *Client) UndeleteTask(taskID ID) (*Task, error) {
 	return &mutation.TaskUndelete, nil
 }
 
-func (c *Client) GetTaskEvents(taskID ID) (*Task, []Event, error) {
+func (c *Client) GetTaskEvents(ctx context.Context, taskID ID) (*Task, []Event, error) {
 	var query struct {
 		Task struct {
 			Task
@@ -218,7 +308,7 @@ func (c *Client) GetTaskEvents(taskID ID) (*Task, []Event, error) {
 		"id": taskID,
 	}
 
-	err := c.Query(&query, variables)
+	err := c.cachedQuery(ctx, &query, variables)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// needsServer tells whether the input takes more than the status to query. Servers not supporting the filters,
+// sorting or paging reject such queries
+func (input *QueryTaskInput) needsServer() bool {
+	return input.TaskFilter != TaskFilter{} || input.Sort != "" || input.Reverse || input.Limit != 0 || input.Offset != 0
+}
+
+// queryTasksLocally queries the tasks by status only, in one query without paging, and filters & sorts them on the
+// client. It's for servers not supporting the filters or paging. Limit & Offset are ignored
+func (c *Client) queryTasksLocally(ctx context.Context, input QueryTaskInput) ([]*Task, error) {
+	f := &input.TaskFilter
+
+	var re *regexp.Regexp
+	if f.Regex != "" {
+		var err error
+		if re, err = regexp.Compile(f.Regex); err != nil {
+			return nil, fmt.Errorf("%w: invalid regex: %s", ErrValidation, err)
+		}
+	}
+
+	var withEvents map[ID]bool
+	if f.EventsAfter != nil || f.EventsBefore != nil {
+		var err error
+		if withEvents, err = c.tasksWithEvents(ctx, f.EventsAfter, f.EventsBefore); err != nil {
+			return nil, err
+		}
+	}
+
+	var parents map[ID]ID
+	if f.ParentID != nil {
+		var err error
+		if parents, err = c.QueryTaskParents(ctx, input.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks, err := c.QueryTasks(ctx, QueryTaskInput{Status: input.Status})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var res []*Task
+	for _, t := range tasks {
+		if f.match(t, re, withEvents, parents, now) {
+			res = append(res, t)
+		}
+	}
+
+	sortTasks(res, input.Sort, input.Reverse)
+	return res, nil
+}
+
+// match checks the task the way the server does. withEvents are the tasks with events in the range, & parents those
+// of the tasks, if filtered by
+func (f *TaskFilter) match(t *Task, re *regexp.Regexp, withEvents map[ID]bool, parents map[ID]ID, now time.Time) bool {
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(string(t.Name)), search) &&
+			!strings.Contains(strings.ToLower(string(t.Description)), search) {
+			return false
+		}
+	}
+	if re != nil && !re.MatchString(string(t.Name)) && !re.MatchString(string(t.Description)) {
+		return false
+	}
+
+	if (f.DueBefore != nil || f.DueAfter != nil || f.Overdue) && t.DueDate == nil {
+		return false
+	}
+	if !inRange((*Time)(t.DueDate), f.DueAfter, f.DueBefore) || !inRange(&t.CreatedAt, f.CreatedAfter, f.CreatedBefore) ||
+		!inRange(&t.UpdatedAt, f.UpdatedAfter, f.UpdatedBefore) {
+		return false
+	}
+	if f.Overdue && !t.IsOverdue(now) {
+		return false
+	}
+
+	if parent, found := parents[t.ID]; f.ParentID != nil && (!found || parent != *f.ParentID) {
+		return false
+	}
+
+	return withEvents == nil || withEvents[t.ID]
+}
+
+func inRange(t, after, before *Time) bool {
+	if t == nil {
+		return true
+	}
+	if after != nil && !time.Time(*t).After(time.Time(*after)) {
+		return false
+	}
+	return before == nil || time.Time(*t).Before(time.Time(*before))
+}
+
+// tasksWithEvents finds the tasks with events started in the range. Open ends are bound by the epoch & tomorrow
+func (c *Client) tasksWithEvents(ctx context.Context, after, before *Time) (map[ID]bool, error) {
+	since, until := time.Unix(0, 0), time.Now().AddDate(0, 0, 1)
+	if after != nil {
+		since = time.Time(*after)
+	}
+	if before != nil {
+		until = time.Time(*before)
+	}
+	if !until.After(since) {
+		return map[ID]bool{}, nil
+	}
+
+	days := int(math.Ceil(until.Sub(since).Hours() / 24))
+	res := map[ID]bool{}
+	it := c.IterateEvents(since, days, 0)
+	for it.Next(ctx) {
+		if e := it.Event(); inRange(&e.StartAt, after, before) {
+			res[e.TaskID] = true
+		}
+	}
+	return res, it.Err()
+}
+
+// sortTasks orders the tasks as the server does: by the sort field, then ID. Tasks without due date go last
+func sortTasks(tasks []*Task, by TaskSort, reverse bool) {
+	less := func(a, b *Task) bool {
+		switch by {
+		case TaskSortDueDate:
+			if (a.DueDate == nil) != (b.DueDate == nil) {
+				return b.DueDate == nil
+			}
+			if a.DueDate != nil && !time.Time(*a.DueDate).Equal(time.Time(*b.DueDate)) {
+				return time.Time(*a.DueDate).Before(time.Time(*b.DueDate))
+			}
+		case TaskSortCreatedAt:
+			if !time.Time(a.CreatedAt).Equal(time.Time(b.CreatedAt)) {
+				return time.Time(a.CreatedAt).Before(time.Time(b.CreatedAt))
+			}
+		case TaskSortUpdatedAt:
+			if !time.Time(a.UpdatedAt).Equal(time.Time(b.UpdatedAt)) {
+				return time.Time(a.UpdatedAt).Before(time.Time(b.UpdatedAt))
+			}
+		case TaskSortName:
+			if nameA, nameB := strings.ToLower(string(a.Name)), strings.ToLower(string(b.Name)); nameA != nameB {
+				return nameA < nameB
+			}
+		}
+		return a.ID < b.ID
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if reverse {
+			return less(tasks[j], tasks[i])
+		}
+		return less(tasks[i], tasks[j])
+	})
+}
+
+// page skips offset items, and keeps up to limit of the rest if limit > 0
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	if offset > 0 {
+		items = items[offset:]
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,199 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/api/apitest"
+)
+
+// legacySDL is the schema of backends before filters, sorting & paging: tasks are queried by status only
+var legacySDL = regexp.MustCompile(`(?s)input QueryTaskInput \{.*?\}`).
+	ReplaceAllString(apitest.SDL, "input QueryTaskInput {\n  status: [TaskStatus!]\n}")
+
+var allStatuses = []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusDone, api.TaskStatusPaused}
+
+func apiTime(t time.Time) *api.Time {
+	return (*api.Time)(&t)
+}
+
+// filterFixture creates the same tasks on any backend. created splits the tasks created before & after it
+type filterFixture struct {
+	created time.Time
+}
+
+func (f *filterFixture) setUp(t *testing.T, client *api.Client) {
+	t.Helper()
+	ctx := context.Background()
+	create := func(name, desc, due string, parent *api.ID) *api.Task {
+		input := api.TaskCreateInput{Name: graphql.String(name), Description: graphql.NewString(graphql.String(desc))}
+		if due != "" {
+			input.DueDate = graphql.NewString(graphql.String(due))
+		}
+		input.ParentID = parent
+		task, err := client.CreateTask(ctx, input)
+		if err != nil {
+			t.Fatalf("CreateTask(%s): %v", name, err)
+		}
+		return task
+	}
+
+	release := create("release v2", "", "2020-01-10", nil)
+	create("write release notes", "for the Weekly Report", "2099-01-05", &release.ID)
+	done := create("weekly report", "", "2020-01-03", nil)
+	if _, err := client.UpdateTask(ctx, done.ID, api.TaskUpdateInput{Status: &api.TaskStatusDone}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	f.created = time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	create("Budget review", "numbers", "", nil)
+	started := create("answer mails", "", "2099-02-01", nil)
+	if _, _, err := client.StartTask(ctx, started.ID); err != nil {
+		t.Fatalf("StartTask: %v", err)
+	}
+}
+
+func (f *filterFixture) cases() []struct {
+	name  string
+	input api.QueryTaskInput
+	want  []string
+} {
+	statuses := allStatuses
+	return []struct {
+		name  string
+		input api.QueryTaskInput
+		want  []string
+	}{
+		{"search in name & description, ignoring case", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{Search: "REPORT"}},
+			[]string{"write release notes", "weekly report"}},
+		{"regex", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{Regex: "^(w|Bu)"}},
+			[]string{"write release notes", "weekly report", "Budget review"}},
+		{"due before", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{DueBefore: apiTime(time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC))}},
+			[]string{"weekly report"}},
+		{"due after", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{DueAfter: apiTime(time.Date(2099, 1, 5, 0, 0, 0, 0, time.UTC))}},
+			[]string{"answer mails"}},
+		{"overdue, not done", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{Overdue: true}},
+			[]string{"release v2"}},
+		{"created after", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{CreatedAfter: apiTime(f.created)}},
+			[]string{"Budget review", "answer mails"}},
+		{"events after", api.QueryTaskInput{Status: statuses, TaskFilter: api.TaskFilter{EventsAfter: apiTime(f.created)}},
+			[]string{"answer mails"}},
+		{"status & filter", api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusNotStarted}, TaskFilter: api.TaskFilter{Search: "re"}},
+			[]string{"release v2", "write release notes", "Budget review"}},
+		{"sort by name, ignoring case", api.QueryTaskInput{Status: statuses, Sort: api.TaskSortName},
+			[]string{"answer mails", "Budget review", "release v2", "weekly report", "write release notes"}},
+		{"sort by due", api.QueryTaskInput{Status: statuses, Sort: api.TaskSortDueDate},
+			[]string{"weekly report", "release v2", "write release notes", "answer mails", "Budget review"}},
+		{"sort by due, reversed", api.QueryTaskInput{Status: statuses, Sort: api.TaskSortDueDate, Reverse: true},
+			[]string{"Budget review", "answer mails", "write release notes", "release v2", "weekly report"}},
+		{"sorted page", api.QueryTaskInput{Status: statuses, Sort: api.TaskSortName, Limit: 2, Offset: 1},
+			[]string{"Budget review", "release v2"}},
+		{"page", api.QueryTaskInput{Status: statuses, Limit: 2, Offset: 3},
+			[]string{"Budget review", "answer mails"}},
+	}
+}
+
+func TestIterateTasksFilters(t *testing.T) {
+	for name, sdl := range map[string]string{"server side": apitest.SDL, "local fallback": legacySDL} {
+		t.Run(name, func(t *testing.T) {
+			srv := apitest.NewServerWithSDL(sdl)
+			defer srv.Close()
+			client := clientOf(srv)
+			var f filterFixture
+			f.setUp(t, client)
+
+			for _, tc := range f.cases() {
+				t.Run(tc.name, func(t *testing.T) {
+					// pages smaller than the results, to page through
+					got := names(iterateAll(t, client.IterateTasks(tc.input, 2)))
+					if len(got) != len(tc.want) {
+						t.Fatalf("got %q, want %q", got, tc.want)
+					}
+					for i := range got {
+						if got[i] != tc.want[i] {
+							t.Fatalf("got %q, want %q", got, tc.want)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestLegacyBackendRejectsFilters(t *testing.T) {
+	srv := apitest.NewServerWithSDL(legacySDL)
+	defer srv.Close()
+	client := clientOf(srv)
+	ctx := context.Background()
+	createTasks(t, client, "a")
+
+	// the fallback has to kick in for paging args alone, as IterateTasks always sends them
+	for _, input := range []api.QueryTaskInput{
+		{Status: allStatuses, TaskFilter: api.TaskFilter{Search: "a"}},
+		{Status: allStatuses, Limit: 10},
+	} {
+		if _, err := client.QueryTasks(ctx, input); !errors.Is(err, api.ErrValidation) {
+			t.Errorf("QueryTasks(%+v) err = %v, want ErrValidation", input, err)
+		}
+	}
+	if tasks, err := client.QueryTasks(ctx, api.QueryTaskInput{Status: allStatuses}); err != nil || len(tasks) != 1 {
+		t.Errorf("QueryTasks by status = %v, %v, want the task", names(tasks), err)
+	}
+
+	if got := iterateAll(t, client.IterateTasks(api.QueryTaskInput{Status: allStatuses}, 0)); len(got) != 1 {
+		t.Errorf("IterateTasks by status = %v, want the task", names(got))
+	}
+}
+
+func TestSortByNameIgnoringCase(t *testing.T) {
+	for name, sdl := range map[string]string{"server side": apitest.SDL, "local fallback": legacySDL} {
+		t.Run(name, func(t *testing.T) {
+			srv := apitest.NewServerWithSDL(sdl)
+			defer srv.Close()
+			client := clientOf(srv)
+			createTasks(t, client, "foo", "bar", "Foo", "FOO")
+
+			// names equal but for the case keep the order of their IDs, reversed too
+			for _, tc := range []struct {
+				reverse bool
+				want    []string
+			}{
+				{false, []string{"bar", "foo", "Foo", "FOO"}},
+				{true, []string{"FOO", "Foo", "foo", "bar"}},
+			} {
+				input := api.QueryTaskInput{Status: allStatuses, Sort: api.TaskSortName, Reverse: tc.reverse}
+				got := names(iterateAll(t, client.IterateTasks(input, 0)))
+				if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+					t.Errorf("reverse=%v: got %q, want %q", tc.reverse, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIterateTasksInvalidRegex(t *testing.T) {
+	for name, sdl := range map[string]string{"server side": apitest.SDL, "local fallback": legacySDL} {
+		t.Run(name, func(t *testing.T) {
+			srv := apitest.NewServerWithSDL(sdl)
+			defer srv.Close()
+			client := clientOf(srv)
+
+			it := client.IterateTasks(api.QueryTaskInput{Status: allStatuses, TaskFilter: api.TaskFilter{Regex: "("}}, 0)
+			if it.Next(context.Background()) {
+				t.Fatal("Next = true, want false for an invalid regex")
+			}
+			if err := it.Err(); !errors.Is(err, api.ErrValidation) {
+				t.Errorf("err = %v, want ErrValidation", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package api_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/api/apitest"
+)
+
+func TestStartTaskPausesRunning(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	tasks := createTasks(t, client, "a", "b")
+
+	started, event, err := client.StartTask(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatalf("StartTask(a): %v", err)
+	}
+	if started.Status != api.TaskStatusDoing || event == nil || event.EndAt != nil {
+		t.Fatalf("StartTask(a) = %s, %+v, want DOING with a running event", started.Status, event)
+	}
+	if id := runningTaskID(t, client); id != tasks[0].ID {
+		t.Fatalf("running task = %d, want a(%d)", id, tasks[0].ID)
+	}
+
+	if _, _, err = client.StartTask(ctx, tasks[1].ID); err != nil {
+		t.Fatalf("StartTask(b): %v", err)
+	}
+	if id := runningTaskID(t, client); id != tasks[1].ID {
+		t.Errorf("running task = %d, want b(%d)", id, tasks[1].ID)
+	}
+	a, events, err := client.GetTaskEvents(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatalf("GetTaskEvents(a): %v", err)
+	}
+	if a.Status != api.TaskStatusPaused {
+		t.Errorf("a status = %s after starting b, want PAUSED", a.Status)
+	}
+	if len(events) != 1 || events[0].EndAt == nil {
+		t.Errorf("events of a = %+v, want one ended", events)
+	}
+}
+
+func TestStartTaskAgainKeepsEvent(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	task := createTasks(t, client, "a")[0]
+
+	_, first, err := client.StartTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("StartTask: %v", err)
+	}
+	_, again, err := client.StartTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("StartTask again: %v", err)
+	}
+	if again.ID != first.ID {
+		t.Errorf("StartTask again started event %d, want the running %d kept", again.ID, first.ID)
+	}
+}
+
+func TestStartTaskWithOffset(t *testing.T) {
+	client, _ := newTestClient(t)
+	task := createTasks(t, client, "a")[0]
+
+	_, event, err := client.StartTask(context.Background(), task.ID, api.StartTaskWithOffset(time.Hour), api.StartTaskWithDescription("standup"))
+	if err != nil {
+		t.Fatalf("StartTask: %v", err)
+	}
+	if ago := time.Since(time.Time(event.StartAt)); ago < time.Hour || ago > time.Hour+time.Minute {
+		t.Errorf("event started %s ago, want an hour", ago)
+	}
+	if event.Description == nil || *event.Description != "standup" {
+		t.Errorf("event description = %v, want standup", event.Description)
+	}
+}
+
+func TestUpdateTaskStopsRunning(t *testing.T) {
+	for _, status := range []api.TaskStatus{api.TaskStatusPaused, api.TaskStatusDone} {
+		t.Run(string(status), func(t *testing.T) {
+			client, _ := newTestClient(t)
+			ctx := context.Background()
+			tasks := createTasks(t, client, "running", "other")
+			if _, _, err := client.StartTask(ctx, tasks[0].ID); err != nil {
+				t.Fatalf("StartTask: %v", err)
+			}
+
+			// changing another task leaves the running one be
+			if _, err := client.UpdateTask(ctx, tasks[1].ID, api.TaskUpdateInput{Status: &status}); err != nil {
+				t.Fatalf("UpdateTask(other): %v", err)
+			}
+			if id := runningTaskID(t, client); id != tasks[0].ID {
+				t.Fatalf("running task = %d after updating another, want %d", id, tasks[0].ID)
+			}
+
+			updated, err := client.UpdateTask(ctx, tasks[0].ID, api.TaskUpdateInput{Status: &status})
+			if err != nil {
+				t.Fatalf("UpdateTask(running): %v", err)
+			}
+			if updated.Status != status {
+				t.Errorf("status = %s, want %s", updated.Status, status)
+			}
+			if id := runningTaskID(t, client); id != 0 {
+				t.Errorf("running task = %d, want none", id)
+			}
+			event, err := client.QueryTaskLastEvent(ctx, tasks[0].ID)
+			if err != nil {
+				t.Fatalf("QueryTaskLastEvent: %v", err)
+			}
+			if event == nil || event.EndAt == nil {
+				t.Errorf("last event = %+v, want it ended", event)
+			}
+		})
+	}
+}
+
+func TestQuerySubtasks(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	parent := createTasks(t, client, "parent", "other")[0]
+	for _, name := range []string{"step 1", "step 2"} {
+		if _, err := client.CreateTask(ctx, api.TaskCreateInput{Name: graphql.String(name), ParentID: &parent.ID}); err != nil {
+			t.Fatalf("CreateTask(%s): %v", name, err)
+		}
+	}
+
+	subtasks, err := client.QuerySubtasks(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("QuerySubtasks: %v", err)
+	}
+	if len(subtasks) != 2 || subtasks[0].Name != "step 1" || subtasks[1].Name != "step 2" {
+		t.Errorf("QuerySubtasks = %v, want step 1 & step 2", names(subtasks))
+	}
+}
+
+func TestQueryTaskParents(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	parent := createTasks(t, client, "parent", "other")[0]
+	step, err := client.CreateTask(ctx, api.TaskCreateInput{Name: "step", ParentID: &parent.ID})
+	if err != nil {
+		t.Fatalf("CreateTask(step): %v", err)
+	}
+
+	parents, err := client.QueryTaskParents(ctx, allStatuses)
+	if err != nil {
+		t.Fatalf("QueryTaskParents: %v", err)
+	}
+	if len(parents) != 1 || parents[step.ID] != parent.ID {
+		t.Errorf("QueryTaskParents = %v, want step(%d) under parent(%d)", parents, step.ID, parent.ID)
+	}
+}
+
+// tasks are queried without their parents, so backends before subtasks still work for everything else
+func TestTasksWithoutParentsBackend(t *testing.T) {
+	srv := apitest.NewServerWithSDL(strings.Replace(apitest.SDL, "  parentID: ID\n  events(", "  events(", 1))
+	defer srv.Close()
+	client := clientOf(srv)
+	ctx := context.Background()
+	tasks := createTasks(t, client, "a", "b")
+
+	if _, _, err := client.StartTask(ctx, tasks[0].ID); err != nil {
+		t.Fatalf("StartTask: %v", err)
+	}
+	if _, err := client.UpdateTask(ctx, tasks[1].ID, api.TaskUpdateInput{Name: graphql.NewString("b2")}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if _, _, err := client.GetTaskEvents(ctx, tasks[0].ID); err != nil {
+		t.Fatalf("GetTaskEvents: %v", err)
+	}
+	if id := runningTaskID(t, client); id != tasks[0].ID {
+		t.Errorf("running task = %d, want a(%d)", id, tasks[0].ID)
+	}
+	got, err := client.QueryTasks(ctx, api.QueryTaskInput{Status: allStatuses})
+	if err != nil {
+		t.Fatalf("QueryTasks: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("QueryTasks = %v, want a & b2", names(got))
+	}
+	if _, err = client.QueryTaskParents(ctx, allStatuses); err == nil {
+		t.Error("QueryTaskParents succeeded, want the backend to reject parentID")
+	}
+}
+
+func names(tasks []*api.Task) []string {
+	res := make([]string, len(tasks))
+	for i, t := range tasks {
+		res[i] = string(t.Name)
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// TokenClaims are the claims of a JWT token about its validity period. They're for display only: the signature
+// isn't verified, the backend is the one to accept or reject the token
+type TokenClaims struct {
+	Subject   string     `json:"subject,omitempty"`
+	IssuedAt  *time.Time `json:"issuedAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Expired tells whether the token is past its expiry time. Tokens without one never expire
+func (c *TokenClaims) Expired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// ParseTokenClaims decodes the claims of a JWT token. ok is false for tokens of other formats
+func ParseTokenClaims(token string) (claims *TokenClaims, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	// the segments are unpadded base64url, though some issuers pad them anyway
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+
+	var raw struct {
+		Subject   any          `json:"sub"`
+		IssuedAt  *json.Number `json:"iat"`
+		ExpiresAt *json.Number `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &raw); err != nil {
+		return nil, false
+	}
+
+	claims = &TokenClaims{IssuedAt: numericDate(raw.IssuedAt), ExpiresAt: numericDate(raw.ExpiresAt)}
+	if raw.Subject != nil {
+		claims.Subject = jsonString(raw.Subject)
+	}
+	return claims, true
+}
+
+// numericDate converts the seconds since epoch of JWT to time, nil if absent or malformed
+func numericDate(n *json.Number) *time.Time {
+	if n == nil {
+		return nil
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(0, int64(seconds*float64(time.Second)))
+	return &t
+}
+
+// jsonString formats a decoded JSON value, as the sub claim may be a string or a number
+func jsonString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
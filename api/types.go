@@ -3,33 +3,53 @@ package api
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/todopeer/cli/util/dt"
 )
 
 type Time time.Time
 type ID int64
 
-func (t *Time) EventTimeOnly() string {
+func (t *Time) EventTimeOnly(loc *time.Location) string {
 	if t == nil {
 		return "doing"
 	}
 
-	return (*time.Time)(t).Local().Format(time.TimeOnly)
+	return (*time.Time)(t).In(loc).Format(time.TimeOnly)
 }
 
-func (t *Time) DateOnly() string {
+func (t *Time) DateOnly(loc *time.Location) string {
 	if t == nil {
 		return "-"
 	}
 
-	return (*time.Time)(t).Local().Format(time.DateOnly)
+	return (*time.Time)(t).In(loc).Format(time.DateOnly)
+}
+
+func (t *Time) DateTime(loc *time.Location) string {
+	if t == nil {
+		return ""
+	}
+
+	return (*time.Time)(t).In(loc).Format(time.DateTime)
 }
 
-func (t *Time) DateTime() string {
+// Relative formats the time relative to now, like "in 3d" or "1h12m ago". Empty for nil
+func (t *Time) Relative(now time.Time) string {
 	if t == nil {
 		return ""
 	}
 
-	return (*time.Time)(t).Local().Format(time.DateTime)
+	return dt.Relative(time.Time(*t), now)
+}
+
+// Day formats the time with its day relative to now's, like "yesterday 14:05", in the time zone. Empty for nil
+func (t *Time) Day(now time.Time, loc *time.Location) string {
+	if t == nil {
+		return ""
+	}
+
+	return dt.Day(time.Time(*t), now, loc)
 }
 
 func (t *Time) UnmarshalJSON(b []byte) error {
@@ -47,12 +67,9 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-func (t *Time) MarshalJSON() ([]byte, error) {
-	var s string
-	if t != nil {
-		s = (*time.Time)(t).Format(time.RFC3339Nano)
-	}
-	return json.Marshal(s)
+// MarshalJSON has a value receiver, so Time fields are encoded even when not addressable
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).Format(time.RFC3339Nano))
 }
 
 func (t *Time) String() string {
@@ -62,3 +79,29 @@ func (t *Time) String() string {
 
 	return (*time.Time)(t).Format(time.DateTime)
 }
+
+// Date is a calendar day, like due dates: given at midnight UTC, it's the same day in any time zone
+type Date Time
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	return (*Time)(d).UnmarshalJSON(b)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return Time(d).MarshalJSON()
+}
+
+// CalendarDay is midnight UTC of the day
+func (d *Date) CalendarDay() time.Time {
+	t := time.Time(*d).UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// String formats the day like 2006-01-02
+func (d *Date) String() string {
+	if d == nil {
+		return ""
+	}
+
+	return d.CalendarDay().Format(time.DateOnly)
+}
@@ -2,14 +2,11 @@ package api
 
 import (
 	"context"
-
 	"github.com/shurcooL/graphql"
 )
 
-// Function to handle the authentication process
-func Login(ctx context.Context, email string, password string) (*AuthPayload, error) {
-	client := graphql.NewClient(gqlAPI, nil)
-
+// Login exchanges the email & password for a token. The client doesn't need to carry a token
+func (c *Client) Login(ctx context.Context, email string, password string) (*AuthPayload, error) {
 	var mutation struct {
 		Login AuthPayload `graphql:"login(input: {email: $email, password: $password})"`
 	}
@@ -19,7 +16,8 @@ func Login(ctx context.Context, email string, password string) (*AuthPayload, er
 		"password": graphql.String(password),
 	}
 
-	err := client.Mutate(ctx, &mutation, variables)
+	// the pending mutations are left to the session logged in
+	err := c.Mutate(withoutReplay(ctx), &mutation, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -33,12 +31,12 @@ type UserWithTask struct {
 	RunningEvent *Event
 }
 
-func (c *Client) MeWithTaskEvent() (user *User, task *Task, event *Event, err error) {
+func (c *Client) MeWithTaskEvent(ctx context.Context) (user *User, task *Task, event *Event, err error) {
 	var query = &struct {
 		Me UserWithTask `graphql:"me"`
 	}{}
 
-	err = c.Query(query, nil)
+	err = c.Query(ctx, query, nil)
 	if err != nil {
 		return
 	}
@@ -50,12 +48,12 @@ func (c *Client) MeWithTaskEvent() (user *User, task *Task, event *Event, err er
 	return
 }
 
-func (c *Client) Me() (*User, error) {
+func (c *Client) Me(ctx context.Context) (*User, error) {
 	var query = &struct {
 		Me User `graphql:"me"`
 	}{}
 
-	err := c.Query(query, nil)
+	err := c.Query(ctx, query, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -64,12 +62,12 @@ func (c *Client) Me() (*User, error) {
 }
 
 // Function to handle the deauthentication process
-func (c *Client) Logout() error {
+func (c *Client) Logout(ctx context.Context) error {
 	var query = &struct {
 		Logout bool `graphql:"logout"`
 	}{}
 
-	err := c.Mutate(query, nil)
+	err := c.Mutate(RetrySafe(ctx), query, nil)
 	if err != nil {
 		return err
 	}
@@ -88,8 +86,8 @@ type AuthPayload struct {
 }
 
 type User struct {
-	ID            graphql.Int    `graphql:"id"`
-	Email         graphql.String `graphql:"email"`
-	Name          graphql.String `graphql:"name"`
-	RunningTaskID *ID            `graphql:"runningTaskID"`
+	ID            graphql.Int    `json:"id" graphql:"id"`
+	Email         graphql.String `json:"email" graphql:"email"`
+	Name          graphql.String `json:"name" graphql:"name"`
+	RunningTaskID *ID            `json:"runningTaskID" graphql:"runningTaskID"`
 }
@@ -1,14 +1,61 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 
+	"github.com/todopeer/cli/api"
 	"github.com/todopeer/cli/commands"
 )
 
+// exit codes, so scripts can tell the failures apart
+const (
+	exitError = 1 + iota
+	exitUnauthenticated
+	exitNotFound
+	exitValidation
+	exitNetwork
+	exitServer
+
+	// following the shell convention of 128 + SIGINT
+	exitInterrupted = 130
+)
+
 func main() {
 	err := commands.Run()
-	if err != nil && err.Error() == "access denied" {
-		fmt.Println("access defined. Probably token expired, try login again.")
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "canceled")
+		os.Exit(exitInterrupted)
+	}
+
+	fmt.Fprintln(os.Stderr, "Error:", err)
+
+	code := exitError
+	switch {
+	case errors.Is(err, api.ErrUnauthenticated):
+		fmt.Fprintln(os.Stderr, "access denied. Probably token expired, try login again.")
+		code = exitUnauthenticated
+	case errors.Is(err, api.ErrNotFound):
+		fmt.Fprintln(os.Stderr, "not found. Check the ID, or whether it's deleted.")
+		code = exitNotFound
+	case errors.Is(err, api.ErrValidation):
+		fmt.Fprintln(os.Stderr, "request rejected by the backend. Check the input, or whether this CLI is outdated.")
+		code = exitValidation
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintln(os.Stderr, "backend didn't respond in time. Retry later, or give a longer --timeout.")
+		code = exitNetwork
+	case errors.Is(err, api.ErrNetwork):
+		fmt.Fprintln(os.Stderr, "backend unreachable. Check your network, or the endpoint of the profile.")
+		code = exitNetwork
+	case errors.Is(err, api.ErrServer):
+		fmt.Fprintln(os.Stderr, "backend failed to handle the request. Please retry later.")
+		code = exitServer
 	}
+	os.Exit(code)
 }
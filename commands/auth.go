@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/services/config"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "inspect the login state",
+}
+
+var statusAuthCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the active profile & the logged in user. Exits non-zero when not logged in",
+	Long: `Shows the active profile, its endpoint and the user of the token, with the issue & expiry time for JWT tokens.
+Exits with 2 when not logged in or the token is rejected, so scripts can check the login state:
+
+todopeer auth status --output json > /dev/null || todopeer login`,
+	Args: cobra.NoArgs,
+	RunE: runAuthStatus,
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "same as auth status",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthStatus,
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, err := config.ActiveProfile()
+	if err != nil {
+		return fmt.Errorf("error loading profile: %w", err)
+	}
+	doc := authStatusDoc{Profile: profile.Name, Endpoint: profileEndpoint(profile), TokenSource: "stored"}
+	if config.TokenFromEnv() {
+		doc.TokenSource = config.TokenEnv
+	}
+
+	token, err := config.ReadToken()
+	if errors.Is(err, os.ErrNotExist) || (err == nil && token == "") {
+		return &api.Error{Kind: api.ErrUnauthenticated, Message: fmt.Sprintf("not logged in with profile %s", profile.Name)}
+	}
+	if err != nil {
+		return fmt.Errorf("error loading token: %w", err)
+	}
+	doc.Token, _ = api.ParseTokenClaims(token)
+
+	client, err := newClient(token)
+	if err != nil {
+		return fmt.Errorf("error loading profile: %w", err)
+	}
+	if doc.User, err = client.Me(ctx); err != nil {
+		if errors.Is(err, api.ErrUnauthenticated) && doc.Token != nil && doc.Token.Expired(time.Now()) {
+			return fmt.Errorf("token expired at %s: %w", doc.Token.ExpiresAt.In(zone).Format(time.DateTime), err)
+		}
+		return err
+	}
+
+	return printResult(doc, func() {
+		fmt.Printf("Logged in to %s with profile %s\n", doc.Endpoint, doc.Profile)
+		fmt.Printf("User:    %d - %s <%s>\n", doc.User.ID, doc.User.Name, doc.User.Email)
+		fmt.Printf("Token:   %s\n", doc.TokenSource)
+		if doc.Token == nil {
+			return
+		}
+		if doc.Token.IssuedAt != nil {
+			fmt.Printf("Issued:  %s\n", doc.Token.IssuedAt.In(zone).Format(time.DateTime))
+		}
+		if doc.Token.ExpiresAt != nil {
+			left := "expired"
+			if !doc.Token.Expired(time.Now()) {
+				left = "in " + time.Until(*doc.Token.ExpiresAt).Round(time.Minute).String()
+			}
+			fmt.Printf("Expires: %s (%s)\n", doc.Token.ExpiresAt.In(zone).Format(time.DateTime), left)
+		}
+	})
+}
+
+func init() {
+	authCmd.AddCommand(statusAuthCmd)
+	rootCmd.AddCommand(authCmd, whoamiCmd)
+}
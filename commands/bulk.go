@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/api"
+)
+
+// maxBulkRange caps ID ranges, against typos like 12-1200
+const maxBulkRange = 1000
+
+// bulkOp is a change applied to tasks in bulk by done, pause, delete, undelete & update
+type bulkOp struct {
+	// verb & past are like "delete" & "deleted"
+	verb, past string
+	apply      func(ctx context.Context, taskID api.ID) (*api.Task, error)
+}
+
+// addBulkFlags adds the flags of acting on tasks in bulk. withWhere adds --where, for commands on tasks that can be
+// queried
+func addBulkFlags(cmd *cobra.Command, withWhere bool) {
+	if withWhere {
+		cmd.Flags().StringVar(&varBulkWhere, "where", "", `act on the tasks matching the expression, like 'status=paused and updated<30d'`)
+	}
+	cmd.Flags().BoolVar(&varBulkIDs, "bulk", false, "act on all the task IDs given, like 12 15. Ranges & --where don't need it")
+	cmd.Flags().BoolVarP(&varBulkYes, "yes", "y", false, "act on multiple tasks without confirming")
+	cmd.Flags().IntVar(&varBulkParallel, "parallel", 4, "changes to make at the same time, when acting on multiple tasks")
+}
+
+// isBulk tells whether the args ask for acting on multiple tasks: by --where, ID ranges like 20-25, or several IDs
+// with --bulk. Without it, args after an ID are the desc, as for done 12 345
+func isBulk(args []string) bool {
+	if varBulkWhere != "" || varBulkIDs {
+		return true
+	}
+	hasRange := false
+	for _, arg := range args {
+		if _, err := strconv.ParseInt(arg, 10, 64); err != nil && !isIDRange(arg) {
+			return false
+		}
+		hasRange = hasRange || isIDRange(arg)
+	}
+	return hasRange
+}
+
+func isIDRange(arg string) bool {
+	from, to, found := strings.Cut(arg, "-")
+	if !found {
+		return false
+	}
+	_, errFrom := strconv.ParseInt(from, 10, 64)
+	_, errTo := strconv.ParseInt(to, 10, 64)
+	return errFrom == nil && errTo == nil
+}
+
+// parseIDArgs gives the IDs of the args, ranges like 20-25 included, without duplicates
+func parseIDArgs(args []string) ([]api.ID, error) {
+	var ids []api.ID
+	seen := map[api.ID]bool{}
+	add := func(id api.ID) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, arg := range args {
+		if !isIDRange(arg) {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("expect task IDs or ranges like 20-25, got: %s", arg)
+			}
+			add(api.ID(id))
+			continue
+		}
+
+		fromStr, toStr, _ := strings.Cut(arg, "-")
+		from, _ := strconv.ParseInt(fromStr, 10, 64)
+		to, _ := strconv.ParseInt(toStr, 10, 64)
+		switch {
+		case from > to:
+			return nil, fmt.Errorf("range %s goes backwards", arg)
+		case to-from >= maxBulkRange:
+			return nil, fmt.Errorf("range %s has over %d tasks", arg, maxBulkRange)
+		}
+		for id := from; id <= to; id++ {
+			add(api.ID(id))
+		}
+	}
+	return ids, nil
+}
+
+// runBulk applies op to the tasks selected by the args or --where, concurrently up to --parallel, once confirmed.
+// It reports how each went, and fails if any did
+func runBulk(ctx context.Context, client *api.Client, args []string, op bulkOp) error {
+	if varBulkParallel < 1 {
+		return errors.New("--parallel must be positive")
+	}
+
+	var ids []api.ID
+	// tasks are known when selected by --where, for the preview
+	var tasks []*api.Task
+	if varBulkWhere != "" {
+		if len(args) > 0 {
+			return errors.New("give either task IDs or --where, not both")
+		}
+		input, err := parseWhere(varBulkWhere, time.Now())
+		if err != nil {
+			return err
+		}
+		it := client.IterateTasks(input, settings.List.PageSize)
+		for it.Next(ctx) {
+			tasks = append(tasks, it.Task())
+			ids = append(ids, it.Task().ID)
+		}
+		if err = it.Err(); err != nil {
+			return fmt.Errorf("error loading tasks of --where: %w", err)
+		}
+	} else {
+		if len(args) == 0 {
+			return errors.New("give the task IDs to act on")
+		}
+		var err error
+		if ids, err = parseIDArgs(args); err != nil {
+			return err
+		}
+	}
+
+	if len(ids) == 0 {
+		notef("no task matches, nothing %s\n", op.past)
+		return nil
+	}
+	if err := confirmBulk(ctx, client, op, ids, tasks); err != nil {
+		return err
+	}
+
+	report := make(bulkReport, len(ids))
+	sem := make(chan struct{}, varBulkParallel)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id api.ID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			t, err := op.apply(ctx, id)
+			report[i] = bulkResult{ID: id, Task: t}
+			switch {
+			case errors.Is(err, api.ErrQueued):
+				report[i].Queued = true
+			case err != nil:
+				report[i].Error = err.Error()
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	var queued, failed int
+	for _, r := range report {
+		switch {
+		case r.Queued:
+			queued++
+		case r.Error != "":
+			failed++
+		}
+	}
+	err := printResult(report, func() {
+		for _, r := range report {
+			switch {
+			case r.Queued:
+				fmt.Printf("queued task(id=%d): backend unreachable, to sync later\n", r.ID)
+			case r.Error != "":
+				fmt.Printf("failed task(id=%d): %s\n", r.ID, r.Error)
+			default:
+				fmt.Printf("%s task(id=%d): %s\n", op.past, r.ID, r.Task.Name)
+			}
+		}
+		fmt.Printf("%d %s, %d queued, %d failed\n", len(report)-queued-failed, op.past, queued, failed)
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tasks failed", failed, len(report))
+	}
+	return nil
+}
+
+// loadTasksByID loads the tasks of the IDs, in their order, by querying the tasks of all statuses until all are
+// found. missing are the IDs of no task, deleted ones included as they can't be queried
+func loadTasksByID(ctx context.Context, client *api.Client, ids []api.ID) (tasks []*api.Task, missing []api.ID, err error) {
+	found := make(map[api.ID]*api.Task, len(ids))
+	for _, id := range ids {
+		found[id] = nil
+	}
+
+	input := api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusDone, api.TaskStatusPaused}}
+	it := client.IterateTasks(input, settings.List.PageSize)
+	for left := len(ids); left > 0 && it.Next(ctx); {
+		if t, wanted := found[it.Task().ID]; wanted && t == nil {
+			found[it.Task().ID] = it.Task()
+			left--
+		}
+	}
+	if err = it.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for _, id := range ids {
+		if t := found[id]; t != nil {
+			tasks = append(tasks, t)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return tasks, missing, nil
+}
+
+// confirmBulk shows the tasks about to change, and asks to go on. Outside terminals, it takes --yes.
+// Tasks not given, as for ID args, are loaded for the preview
+func confirmBulk(ctx context.Context, client *api.Client, op bulkOp, ids []api.ID, tasks []*api.Task) error {
+	if varBulkYes {
+		return nil
+	}
+	if !isTerminal() {
+		return fmt.Errorf("to %s %d tasks, confirm with --yes", op.verb, len(ids))
+	}
+
+	var missing []api.ID
+	if tasks == nil {
+		var err error
+		if tasks, missing, err = loadTasksByID(ctx, client, ids); err != nil {
+			// the changes may still be queued, as when the backend is unreachable
+			fmt.Fprintf(os.Stderr, "error loading the tasks for the preview: %v\n", err)
+			tasks, missing = nil, ids
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "about to %s %d tasks:\n", op.verb, len(ids))
+	preview := newTaskTable()
+	preview.W = os.Stderr
+	now := time.Now()
+	for _, t := range tasks {
+		preview.Row(taskCells(t, now)...)
+	}
+	preview.Flush()
+	if len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, id := range missing {
+			names[i] = strconv.FormatInt(int64(id), 10)
+		}
+		fmt.Fprintf(os.Stderr, "  not found, or deleted: %s\n", strings.Join(names, ", "))
+	}
+	fmt.Fprint(os.Stderr, "go on? [y/N] ")
+
+	line, err := readLine(bufio.NewReader(os.Stdin))
+	if err != nil {
+		return fmt.Errorf("read input failed: %w", err)
+	}
+	if answer := strings.ToLower(line); answer != "y" && answer != "yes" {
+		return errors.New("canceled, no task changed")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/todopeer/cli/api"
+)
+
+func TestIsBulk(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"12"}, false},
+		{[]string{"write report"}, false},
+		{[]string{"20-25"}, true},
+		{[]string{"12", "20-25"}, true},
+		// a task ID with the description of the event, numeric or not
+		{[]string{"12", "standup"}, false},
+		{[]string{"12", "345"}, false},
+		{[]string{"a-b"}, false},
+	} {
+		if got := isBulk(tc.args); got != tc.want {
+			t.Errorf("isBulk(%q) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+
+	varBulkIDs = true
+	if !isBulk([]string{"12", "345"}) {
+		t.Error("isBulk(12 345) with --bulk = false, want true")
+	}
+	varBulkIDs = false
+
+	varBulkWhere = "status=paused"
+	defer func() { varBulkWhere = "" }()
+	if !isBulk(nil) {
+		t.Error("isBulk with --where = false, want true")
+	}
+}
+
+func TestParseIDArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want []api.ID
+	}{
+		{[]string{"3"}, []api.ID{3}},
+		{[]string{"3", "1", "2"}, []api.ID{3, 1, 2}},
+		{[]string{"20-23"}, []api.ID{20, 21, 22, 23}},
+		{[]string{"5-5"}, []api.ID{5}},
+		{[]string{"2", "1-3", "3"}, []api.ID{2, 1, 3}},
+	} {
+		got, err := parseIDArgs(tc.args)
+		if err != nil {
+			t.Errorf("parseIDArgs(%q): %v", tc.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseIDArgs(%q) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"x"},
+		{"1", "x"},
+		{"5-3"},
+		{"1-" + strconv.Itoa(maxBulkRange+1)},
+	} {
+		if got, err := parseIDArgs(args); err == nil {
+			t.Errorf("parseIDArgs(%q) = %v, want an error", args, got)
+		}
+	}
+}
@@ -1,28 +1,128 @@
 package commands
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/output"
 )
 
-var debugMode = false
+var (
+	debugMode   = false
+	flagProfile string
+
+	// settings are loaded before running any command
+	settings    = config.DefaultSettings()
+	flagTimeout time.Duration
+	// flagTimeZone overrides the time_zone setting
+	flagTimeZone string
+	// zone is the time zone times are shown & entered in, see setupTimeZone
+	zone = time.Local
+
+	flagRetry = api.DefaultRetryPolicy
+)
 
 var rootCmd = &cobra.Command{
 	Use: "todopeer",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	// errors are reported by the caller of Run
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// args are parsed by now, the usage doesn't help with errors from here on
+		cmd.SilenceUsage = true
+
 		if debugMode {
 			api.SetLogger(log.Printf)
 		}
+		if flagProfile != "" {
+			config.SelectProfile(flagProfile)
+		}
+		if err := loadSettings(cmd); err != nil {
+			return err
+		}
+		if err := setupTimeZone(); err != nil {
+			return err
+		}
+		return setupPrinter()
 	},
 	Short: "a CLI for interacting with your Todopeer Backend",
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "enable debug mode")
+	rootCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 30*time.Second, "timeout for each request to the backend, 0 for no timeout")
+	rootCmd.PersistentFlags().IntVar(&flagRetry.MaxRetries, "retries", flagRetry.MaxRetries, "max retries of queries on network errors, 0 to disable")
+	rootCmd.PersistentFlags().DurationVar(&flagRetry.MaxDelay, "retry-max-delay", flagRetry.MaxDelay, "max backoff delay between retries")
+	rootCmd.PersistentFlags().StringVar(&flagOutput, "output", string(output.FormatText), fmt.Sprintf("output format, one of %v", output.Formats))
+	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", "", "Go template to render the result with, like '{{.ID}} {{.Name}}'. Lists render one item per line.\nHelpers: duration, date, time, datetime, relative, day, json, upper, lower")
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "disable colors in the text output, also by setting NO_COLOR")
+	rootCmd.PersistentFlags().BoolVar(&flagAbsoluteTimes, "absolute-times", false, "show dates & times as is, instead of relative to now like \"due in 3d\" in the text output")
+	rootCmd.PersistentFlags().StringVar(&flagTimeZone, "tz", "", "time zone to show & enter times in, like Europe/Berlin or UTC. Default to the time_zone setting, or the system's")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "profile to use, default to $"+config.EnvProfile+" or the one set by \"profile use\"")
+}
+
+// newClient creates the api client for the active profile, with the given token.
+// Logged in clients queue mutations while the backend is unreachable, see syncCmd, and cache the tasks & events read
+func newClient(token string) (*api.Client, error) {
+	profile, err := config.ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []api.ClientOptionFunc{api.WithEndpoint(profile.Endpoint), api.WithTimeout(flagTimeout), api.WithRetry(flagRetry),
+		api.WithLookback(settings.Report.LookbackDays)}
+	if token != "" {
+		journal, err := config.ActiveJournal()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, api.WithJournal(journal, noteSynced))
+
+		cache, err := config.ActiveCache()
+		if err != nil {
+			return nil, err
+		}
+		mode := api.CacheFresh
+		if varCached {
+			mode = api.CacheFirst
+		}
+		options = append(options, api.WithCache(cache, mode))
+	}
+	return api.NewClient(token, options...), nil
+}
+
+// addCacheFlags adds --cached & --fresh to commands reading tasks or events. Such commands must wait for the client
+// before exiting, see api.Client.Wait
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&varCached, "cached", false, "answer from the local cache when possible, refreshing it in the background")
+	cmd.Flags().BoolVar(&varFresh, "fresh", false, "always query the backend (default)")
+	cmd.MarkFlagsMutuallyExclusive("cached", "fresh")
+}
+
+// mustGetClient creates the api client for the active profile. Exits if not logged in
+func mustGetClient() *api.Client {
+	client, err := newClient(config.MustGetToken())
+	if err != nil {
+		log.Fatal("Error loading profile: ", err)
+	}
+	return client
 }
 
+// Run executes the command. Ctrl-C cancels the in-flight requests; a 2nd Ctrl-C kills the process
 func Run() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return rootCmd.ExecuteContext(ctx)
 }
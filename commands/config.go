@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/output"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "manage the settings: defaults of commands & preferences, kept in config.yaml of the config dir",
+	Long: `Settings are kept in config.yaml of the config dir ($XDG_CONFIG_HOME/todopeer, default to ~/.config/todopeer).
+Flags override them. Keys are like pomodoro.duration, see "config list" for all of them.`,
+}
+
+var getConfigCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "print a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := config.LoadSettings()
+		if err != nil {
+			return err
+		}
+		value, err := s.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return printResult(settingDoc{Key: args[0], Value: value}, func() {
+			fmt.Println(value)
+		})
+	},
+}
+
+var setConfigCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "change a setting. Lists are comma-separated, like: config set list.status n,i",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := config.LoadSettings()
+		if err != nil {
+			return fmt.Errorf("%w; fix it with: config edit", err)
+		}
+		if err = s.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		if err = validateSettings(s); err != nil {
+			return err
+		}
+		if err = config.SaveSettings(s); err != nil {
+			return err
+		}
+
+		value, _ := s.Get(args[0])
+		notef("%s set to %s\n", args[0], value)
+		return nil
+	},
+}
+
+var listConfigCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l"},
+	Short:   "list all settings",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := config.LoadSettings()
+		if err != nil {
+			return err
+		}
+
+		var list settingList
+		for _, key := range config.SettingKeys() {
+			value, err := s.Get(key)
+			if err != nil {
+				return err
+			}
+			list = append(list, settingDoc{Key: key, Value: value})
+		}
+
+		return printResult(list, func() {
+			for _, setting := range list {
+				fmt.Printf("%s = %s\n", setting.Key, setting.Value)
+			}
+		})
+	},
+}
+
+var editConfigCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "edit the settings file with $VISUAL or $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := config.SettingsFile()
+		if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+			// start from the defaults, so all the settings are there to edit
+			if err = config.SaveSettings(config.DefaultSettings()); err != nil {
+				return err
+			}
+		}
+
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		// the editor may come with args, like "code --wait"
+		fields := strings.Fields(editor)
+		edit := exec.CommandContext(cmd.Context(), fields[0], append(fields[1:], file)...)
+		edit.Stdin, edit.Stdout, edit.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := edit.Run(); err != nil {
+			return fmt.Errorf("error running editor %s: %w", editor, err)
+		}
+
+		s, err := config.LoadSettings()
+		if err == nil {
+			err = validateSettings(s)
+		}
+		if err != nil {
+			return fmt.Errorf("%w; the file is kept, edit again to fix it", err)
+		}
+		return nil
+	},
+}
+
+type settingDoc struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (d settingDoc) Header() []string {
+	return []string{"key", "value"}
+}
+
+func (d settingDoc) Rows() [][]string {
+	return [][]string{{d.Key, d.Value}}
+}
+
+type settingList []settingDoc
+
+func (l settingList) Header() []string {
+	return settingDoc{}.Header()
+}
+
+func (l settingList) Rows() [][]string {
+	res := make([][]string, len(l))
+	for i, d := range l {
+		res[i] = []string{d.Key, d.Value}
+	}
+	return res
+}
+
+// setupTimeZone sets the time zone times are shown & entered in: --tz, or the time_zone setting, default to the system's
+func setupTimeZone() error {
+	name := flagTimeZone
+	if name == "" {
+		name = settings.TimeZone
+	}
+	if name == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid time zone: %w", err)
+	}
+	zone = loc
+	return nil
+}
+
+// loadSettings loads the settings, and applies the ones with global effect. Flags given override them
+func loadSettings(cmd *cobra.Command) error {
+	s, err := config.LoadSettings()
+	if err == nil {
+		err = validateSettings(s)
+	}
+	if err != nil {
+		// the config commands still run, to fix the settings
+		for c := cmd; c != nil; c = c.Parent() {
+			if c == configCmd {
+				return nil
+			}
+		}
+		return fmt.Errorf("error in settings: %w; fix it with: config edit", err)
+	}
+	settings = s
+
+	if !cmd.Flags().Changed("output") {
+		flagOutput = s.Output.Format
+	}
+	return nil
+}
+
+func validateSettings(s *config.Settings) error {
+	for _, status := range s.List.Status {
+		if _, found := mapStatusShort2TaskStatus[status]; !found {
+			return fmt.Errorf("list.status: unknown status %s", status)
+		}
+	}
+	if _, found := mapSortFlag2TaskSort[s.List.Sort]; !found {
+		return fmt.Errorf("list.sort: unknown sort %s, expect one of id, due, created, updated & name", s.List.Sort)
+	}
+	if s.List.PageSize <= 0 {
+		return errors.New("list.page_size must be positive")
+	}
+	if s.Pomodoro.Duration <= 0 || s.Pomodoro.Break <= 0 {
+		return errors.New("pomodoro.duration & pomodoro.break must be positive")
+	}
+	if s.Report.LookbackDays <= 0 {
+		return errors.New("report.lookback_days must be positive")
+	}
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return fmt.Errorf("time_zone: %w", err)
+		}
+	}
+	if _, err := output.ParseFormat(s.Output.Format); err != nil {
+		return fmt.Errorf("output.format: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(getConfigCmd, setConfigCmd, listConfigCmd, editConfigCmd)
+	rootCmd.AddCommand(configCmd)
+}
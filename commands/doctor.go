@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/gql"
+)
+
+func init() {
+	schemaDoctorCmd.Flags().StringVar(&varDoctorSchema, "schema", "", "check against the schema in the file, as SDL or introspection JSON, instead of querying the backend")
+	schemaDoctorCmd.Flags().StringVar(&varDoctorSave, "save", "", "save the introspection result to the file, for later checks with --schema")
+
+	doctorCmd.AddCommand(schemaDoctorCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "diagnose problems with the setup",
+}
+
+var schemaDoctorCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "check the operations the client sends are compatible with the backend schema",
+	Long: `The backend schema is fetched by introspection, or loaded with --schema from a file.
+Every query & mutation the client sends is checked against it: missing or renamed fields & arguments,
+type mismatches, both in the documents and in how responses are decoded, and deprecated usages.
+
+Exits with an error if any incompatibility is found. Deprecations are warnings only.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if varDoctorSchema != "" && varDoctorSave != "" {
+			return fmt.Errorf("--save only applies when querying the backend, not with --schema")
+		}
+
+		schema, err := loadSchema(cmd)
+		if err != nil {
+			return err
+		}
+
+		issues := schemaIssueList(api.CheckSchema(schema))
+		var errCount int
+		for _, issue := range issues {
+			if issue.Severity == gql.SeverityError {
+				errCount++
+			}
+		}
+
+		printErr := printResult(issues, func() {
+			if len(issues) == 0 {
+				fmt.Printf("all %d operations compatible with the schema\n", len(api.Operations()))
+				return
+			}
+			for _, issue := range issues {
+				location := issue.Method
+				if issue.Path != "" {
+					location += " " + issue.Path
+				}
+				fmt.Printf("%s\t%s: %s\n", issue.Severity, location, issue.Message)
+			}
+		})
+		if errCount > 0 {
+			return fmt.Errorf("%d incompatibilities found", errCount)
+		}
+		return printErr
+	},
+}
+
+// loadSchema loads the schema from --schema, or by introspection of the backend
+func loadSchema(cmd *cobra.Command) (*gql.Schema, error) {
+	if varDoctorSchema != "" {
+		b, err := readFileOrStdin(varDoctorSchema)
+		if err != nil {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+			return gql.FromIntrospection(b)
+		}
+		return gql.ParseSchema(string(b))
+	}
+
+	// introspection doesn't need to be logged in
+	token, _ := config.ReadToken()
+	client, err := newClient(token)
+	if err != nil {
+		return nil, fmt.Errorf("error loading profile: %w", err)
+	}
+
+	resp, err := client.Raw(cmd.Context(), gql.IntrospectionQuery, nil, "IntrospectionQuery")
+	if err != nil {
+		return nil, fmt.Errorf("error querying the schema: %w", err)
+	}
+
+	if varDoctorSave != "" {
+		var buf bytes.Buffer
+		if err = json.Indent(&buf, resp.Data, "", "  "); err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(varDoctorSave, buf.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		notef("schema saved to %s\n", varDoctorSave)
+	}
+	return gql.FromIntrospection(resp.Data)
+}
+
+type schemaIssueList []api.SchemaIssue
+
+func (l schemaIssueList) Header() []string {
+	return []string{"method", "severity", "path", "message"}
+}
+
+func (l schemaIssueList) Rows() [][]string {
+	res := make([][]string, len(l))
+	for i, issue := range l {
+		res[i] = []string{issue.Method, string(issue.Severity), issue.Path, issue.Message}
+	}
+	return res
+}
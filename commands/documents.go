@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/services/config"
+)
+
+// the documents emitted by commands in structured output mode
+
+var (
+	taskHeader  = []string{"id", "status", "name", "description", "due_date", "created_at", "updated_at"}
+	eventHeader = []string{"id", "task_id", "start_at", "end_at", "description"}
+)
+
+func csvTime(t *api.Time) string {
+	if t == nil {
+		return ""
+	}
+	return (*time.Time)(t).Format(time.RFC3339)
+}
+
+func csvStdTime(t *time.Time) string {
+	return csvTime((*api.Time)(t))
+}
+
+func csvString[T ~string](s *T) string {
+	if s == nil {
+		return ""
+	}
+	return string(*s)
+}
+
+func taskRow(t *api.Task) []string {
+	return []string{strconv.FormatInt(int64(t.ID), 10), string(t.Status), string(t.Name), string(t.Description),
+		csvTime((*api.Time)(t.DueDate)), csvTime(&t.CreatedAt), csvTime(&t.UpdatedAt)}
+}
+
+func eventRow(e *api.Event) []string {
+	return []string{strconv.FormatInt(int64(e.ID), 10), strconv.FormatInt(int64(e.TaskID), 10),
+		csvTime(&e.StartAt), csvTime(e.EndAt), csvString(e.Description)}
+}
+
+// seconds is a duration, encoded as seconds in JSON
+type seconds time.Duration
+
+func (s seconds) Duration() time.Duration {
+	return time.Duration(s)
+}
+
+func (s seconds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(time.Duration(s) / time.Second))
+}
+
+// taskDoc is for commands acting on a single task
+type taskDoc struct {
+	*api.Task
+}
+
+func (d taskDoc) Header() []string {
+	return taskHeader
+}
+
+func (d taskDoc) Rows() [][]string {
+	return [][]string{taskRow(d.Task)}
+}
+
+// eventDoc is for commands acting on a single event
+type eventDoc struct {
+	*api.Event
+}
+
+func (d eventDoc) Header() []string {
+	return eventHeader
+}
+
+func (d eventDoc) Rows() [][]string {
+	return [][]string{eventRow(d.Event)}
+}
+
+// taskEventDoc is for commands acting on a task, and optionally its event
+type taskEventDoc struct {
+	Task  *api.Task  `json:"task"`
+	Event *api.Event `json:"event"`
+}
+
+func (d taskEventDoc) Header() []string {
+	return append(append([]string{}, taskHeader...), prefixed("event_", eventHeader)...)
+}
+
+func (d taskEventDoc) Rows() [][]string {
+	row := taskRow(d.Task)
+	if d.Event != nil {
+		row = append(row, eventRow(d.Event)...)
+	} else {
+		row = append(row, make([]string, len(eventHeader))...)
+	}
+	return [][]string{row}
+}
+
+// taskEventsDoc is a task with all its events, and its subtasks. In CSV, one row per event
+type taskEventsDoc struct {
+	Task     *api.Task      `json:"task"`
+	Events   []api.Event    `json:"events"`
+	Subtasks []subtaskSpent `json:"subtasks"`
+}
+
+// subtaskSpent is a subtask with the total time tracked on it
+type subtaskSpent struct {
+	*api.Task
+	Spent seconds `json:"spentSeconds"`
+}
+
+func (d taskEventsDoc) Header() []string {
+	return append([]string{"task_id", "task_name"}, prefixed("event_", eventHeader)...)
+}
+
+func (d taskEventsDoc) Rows() [][]string {
+	res := make([][]string, len(d.Events))
+	for i := range d.Events {
+		res[i] = append([]string{strconv.FormatInt(int64(d.Task.ID), 10), string(d.Task.Name)}, eventRow(&d.Events[i])...)
+	}
+	return res
+}
+
+// authStatusDoc is the login state of the active profile. Token is nil for tokens other than JWT
+type authStatusDoc struct {
+	Profile  string `json:"profile"`
+	Endpoint string `json:"endpoint"`
+	// TokenSource is where the token comes from: stored, or the env var
+	TokenSource string           `json:"tokenSource"`
+	User        *api.User        `json:"user"`
+	Token       *api.TokenClaims `json:"token"`
+}
+
+func (d authStatusDoc) Header() []string {
+	return []string{"profile", "endpoint", "token_source", "user_id", "email", "user_name", "issued_at", "expires_at"}
+}
+
+func (d authStatusDoc) Rows() [][]string {
+	row := []string{d.Profile, d.Endpoint, d.TokenSource, strconv.Itoa(int(d.User.ID)), string(d.User.Email), string(d.User.Name), "", ""}
+	if d.Token != nil {
+		row[6] = csvStdTime(d.Token.IssuedAt)
+		row[7] = csvStdTime(d.Token.ExpiresAt)
+	}
+	return [][]string{row}
+}
+
+// meDoc is the current user, with the running task & event
+type meDoc struct {
+	User  *api.User  `json:"user"`
+	Task  *api.Task  `json:"task"`
+	Event *api.Event `json:"event"`
+}
+
+func (d meDoc) Header() []string {
+	return append([]string{"user_id", "email", "user_name", "task_id", "task_name"}, prefixed("event_", eventHeader)...)
+}
+
+func (d meDoc) Rows() [][]string {
+	row := []string{strconv.Itoa(int(d.User.ID)), string(d.User.Email), string(d.User.Name), "", ""}
+	if d.Task != nil {
+		row[3] = strconv.FormatInt(int64(d.Task.ID), 10)
+		row[4] = string(d.Task.Name)
+	}
+	if d.Event != nil {
+		row = append(row, eventRow(d.Event)...)
+	} else {
+		row = append(row, make([]string, len(eventHeader))...)
+	}
+	return [][]string{row}
+}
+
+// dayEvent is an event in the day summary, with its task name & time spent
+type dayEvent struct {
+	api.Event
+	TaskName string  `json:"taskName"`
+	Spent    seconds `json:"spentSeconds"`
+}
+
+// taskSpent is the total time spent on a task within a day
+type taskSpent struct {
+	ID    api.ID  `json:"id"`
+	Name  string  `json:"name"`
+	Spent seconds `json:"spentSeconds"`
+}
+
+// daySummary is the document of the `day` command. In CSV, one row per event
+type daySummary struct {
+	Date   string      `json:"date"`
+	Events []dayEvent  `json:"events"`
+	Tasks  []taskSpent `json:"tasks"`
+	Total  seconds     `json:"totalSeconds"`
+}
+
+func (d daySummary) Header() []string {
+	return append(append([]string{}, eventHeader...), "task_name", "spent_seconds")
+}
+
+func (d daySummary) Rows() [][]string {
+	res := make([][]string, len(d.Events))
+	for i := range d.Events {
+		e := &d.Events[i]
+		res[i] = append(eventRow(&e.Event), e.TaskName, strconv.FormatInt(int64(time.Duration(e.Spent)/time.Second), 10))
+	}
+	return res
+}
+
+var pendingHeader = []string{"kind", "task_id", "at", "status", "description"}
+
+func pendingRow(m *api.PendingMutation) []string {
+	var status string
+	if m.Input != nil {
+		status = csvString(m.Input.Status)
+	}
+	return []string{string(m.Kind), strconv.FormatInt(int64(m.TaskID), 10), csvTime(&m.At), status, csvString(m.Description)}
+}
+
+// pendingList is the mutations queued for sync
+type pendingList []api.PendingMutation
+
+func (l pendingList) Header() []string {
+	return pendingHeader
+}
+
+func (l pendingList) Rows() [][]string {
+	res := make([][]string, len(l))
+	for i := range l {
+		res[i] = pendingRow(&l[i])
+	}
+	return res
+}
+
+// syncResult is a replayed mutation. Conflict tells why it's dropped, if so
+type syncResult struct {
+	api.PendingMutation
+	Task     *api.Task  `json:"task"`
+	Event    *api.Event `json:"event"`
+	Conflict string     `json:"conflict,omitempty"`
+}
+
+type syncReport []syncResult
+
+func (r syncReport) Header() []string {
+	return append(append([]string{}, pendingHeader...), "event_id", "conflict")
+}
+
+func (r syncReport) Rows() [][]string {
+	res := make([][]string, len(r))
+	for i := range r {
+		var eventID string
+		if r[i].Event != nil {
+			eventID = strconv.FormatInt(int64(r[i].Event.ID), 10)
+		}
+		res[i] = append(pendingRow(&r[i].PendingMutation), eventID, r[i].Conflict)
+	}
+	return res
+}
+
+func prefixed(prefix string, header []string) []string {
+	res := make([]string, len(header))
+	for i, h := range header {
+		res[i] = prefix + h
+	}
+	return res
+}
+
+// bulkResult is how a change to one of the tasks acted on in bulk went. Task is nil if it failed
+type bulkResult struct {
+	ID     api.ID    `json:"id"`
+	Task   *api.Task `json:"task"`
+	Queued bool      `json:"queued,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+type bulkReport []bulkResult
+
+func (r bulkReport) Header() []string {
+	return append(append([]string{}, taskHeader...), "queued", "error")
+}
+
+func (r bulkReport) Rows() [][]string {
+	res := make([][]string, len(r))
+	for i, item := range r {
+		row := make([]string, len(taskHeader))
+		if item.Task != nil {
+			row = taskRow(item.Task)
+		}
+		row[0] = strconv.FormatInt(int64(item.ID), 10)
+		res[i] = append(row, strconv.FormatBool(item.Queued), item.Error)
+	}
+	return res
+}
+
+// recurringDoc is a recurring task. Next is the due date of the next task to create, empty once the rule ended
+type recurringDoc struct {
+	config.Recurring
+	Next string `json:"next,omitempty"`
+}
+
+var recurringHeader = []string{"id", "name", "description", "rule", "start", "last", "next"}
+
+func (d recurringDoc) Header() []string {
+	return recurringHeader
+}
+
+func (d recurringDoc) Rows() [][]string {
+	return [][]string{d.row()}
+}
+
+func (d recurringDoc) row() []string {
+	return []string{strconv.Itoa(d.ID), d.Name, d.Description, d.Rule, d.Start, d.Last, d.Next}
+}
+
+type recurringList []recurringDoc
+
+func (l recurringList) Header() []string {
+	return recurringHeader
+}
+
+func (l recurringList) Rows() [][]string {
+	res := make([][]string, len(l))
+	for i, d := range l {
+		res[i] = d.row()
+	}
+	return res
+}
+
+// recurRunResult is an occurrence of a recurring task handled by "recur run". Existed tells the task was created
+// before, and Task is nil on errors & dry runs
+type recurRunResult struct {
+	RecurringID int       `json:"recurringID"`
+	Name        string    `json:"name"`
+	Due         string    `json:"due,omitempty"`
+	Task        *api.Task `json:"task"`
+	Existed     bool      `json:"existed,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type recurRunReport []recurRunResult
+
+func (r recurRunReport) Header() []string {
+	return []string{"recurring_id", "name", "due", "task_id", "existed", "error"}
+}
+
+func (r recurRunReport) Rows() [][]string {
+	res := make([][]string, len(r))
+	for i, item := range r {
+		var taskID string
+		if item.Task != nil {
+			taskID = strconv.FormatInt(int64(item.Task.ID), 10)
+		}
+		res[i] = []string{strconv.Itoa(item.RecurringID), item.Name, item.Due, taskID, strconv.FormatBool(item.Existed), item.Error}
+	}
+	return res
+}
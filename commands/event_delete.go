@@ -7,7 +7,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 func init() {
@@ -19,14 +18,14 @@ var deleteEventCmd = &cobra.Command{
 	Aliases: []string{"de"},
 	Short:   "delete-event (de) a event by its ID",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
 		var eventID api.ID
 		var err error
 		if len(args) == 0 {
-			fmt.Println("eventID not provided, would use current running event")
-			evt, err := client.QueryRunningEvent()
+			notef("eventID not provided, would use current running event\n")
+			evt, err := client.QueryRunningEvent(ctx)
 			if err != nil {
 				return err
 			}
@@ -43,11 +42,12 @@ var deleteEventCmd = &cobra.Command{
 			eventID = api.ID(eventIDInt)
 		}
 
-		t, err := client.DeleteEvent(eventID)
+		t, err := client.DeleteEvent(ctx, eventID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("event(id=%d) deleted successfully\n", t.ID)
-		return err
+		return printResult(eventDoc{t}, func() {
+			fmt.Printf("event(id=%d) deleted successfully\n", t.ID)
+		})
 	},
 }
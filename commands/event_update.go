@@ -9,7 +9,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 	"github.com/todopeer/cli/util/gql"
 )
 
@@ -36,12 +35,12 @@ update-event [event-id]: update event. Details are provided as flags
 update-event: update the current running event. Errors if no running event
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
 		var eventID api.ID
 		if len(args) == 0 {
-			runningEvent, err := client.QueryRunningEvent()
+			runningEvent, err := client.QueryRunningEvent(ctx)
 			if err != nil {
 				return fmt.Errorf("error querying running event: %w", err)
 			}
@@ -58,7 +57,7 @@ update-event: update the current running event. Errors if no running event
 			eventID = api.ID(eventIDInt)
 		}
 
-		event, err := client.GetEvent(api.ID(eventID))
+		event, err := client.GetEvent(ctx, api.ID(eventID))
 		if err != nil {
 			return err
 		}
@@ -78,12 +77,12 @@ update-event: update the current running event. Errors if no running event
 		if varDescription != "" {
 			input.Description = gql.ToGqlStringP(varDescription)
 		}
-		input.StartAt, err = parsePointOfTime(startTimeP, dayOffset, varStartAtStr)
+		input.StartAt, err = parsePointOfTime(startTimeP, dayOffset, varStartAtStr, zone)
 		if err != nil {
 			return fmt.Errorf("err parse startInput: %w", err)
 		}
 
-		input.EndAt, err = parsePointOfTime(endTimeP, dayOffset, varEndAtStr)
+		input.EndAt, err = parsePointOfTime(endTimeP, dayOffset, varEndAtStr, zone)
 		if err != nil {
 			return fmt.Errorf("err parse endInput: %w", err)
 		}
@@ -109,17 +108,20 @@ update-event: update the current running event. Errors if no running event
 			input.TaskID = (*api.ID)(&taskID)
 		}
 
-		e, err := client.UpdateEvent(api.ID(eventID), input)
+		e, err := client.UpdateEvent(ctx, api.ID(eventID), input)
 		if err != nil {
 			return err
 		}
-		fmt.Println("event successfully updated")
-		api.EventFormatter{}.Output(e)
-		return nil
+		return printResult(eventDoc{e}, func() {
+			fmt.Println("event successfully updated")
+			api.EventFormatter{Humanize: !flagAbsoluteTimes, Location: zone}.Output(e)
+		})
 	},
 }
 
-func parsePointOfTime(dateReference *time.Time, dayOffset int, s string) (*api.Time, error) {
+// parsePointOfTime parses s as a duration relative to the reference (default to now), or a wall-clock time like
+// 09:30 of the day of the reference, in the time zone
+func parsePointOfTime(dateReference *time.Time, dayOffset int, s string, loc *time.Location) (*api.Time, error) {
 	if len(s) == 0 {
 		return nil, nil
 	}
@@ -136,10 +138,9 @@ func parsePointOfTime(dateReference *time.Time, dayOffset int, s string) (*api.T
 
 	if dateReference == nil {
 		dateReference = &now
-	} else {
-		localDate := dateReference.Local()
-		dateReference = &localDate
 	}
+	localDate := dateReference.In(loc)
+	dateReference = &localDate
 
 	nd := dateReference.AddDate(0, 0, dayOffset)
 	dateReference = &nd
@@ -7,9 +7,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 	"github.com/todopeer/cli/util/dt"
 	"github.com/todopeer/cli/util/maps"
+	"github.com/todopeer/cli/util/table"
 )
 
 func getDayOffset(s string) (int, error) {
@@ -28,79 +28,106 @@ func getDayOffset(s string) (int, error) {
 	}
 }
 
+// parseDayArgs gives the start of the day asked for, in the time zone: today without args
+func parseDayArgs(args []string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if len(args) == 0 {
+		return today, nil
+	}
+
+	param := args[0]
+	if param[0] == 'p' {
+		dayOffset, err := getDayOffset(param)
+		if err != nil {
+			return time.Time{}, err
+		}
+		// days around DST changes aren't 24h long
+		return today.AddDate(0, 0, dayOffset), nil
+	}
+	// expect to be a specific
+	return dt.FromDate(param, loc)
+}
+
 var listEventsCommand = &cobra.Command{
 	Use:   "day",
 	Short: "show events of a day, default to today.",
 	Long:  "Can pass in `p[n]` to see n-th day before today, or [YYYY-MM-DD] to see a specific day",
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
-
-		now := time.Now()
-		dayForQuery := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
-
-		if len(args) > 0 {
-			param := args[0]
-			if param[0] == 'p' {
-				dayOffset, err := getDayOffset(param)
-				if err != nil {
-					return err
-				}
-				dayForQuery = dayForQuery.Add(time.Duration(dayOffset) * time.Hour * 24)
-			} else {
-				// expect to be a specific
-				dayForQuery, err = dt.FromDate(param)
-				if err != nil {
-					return err
-				}
-			}
-		}
+		client := mustGetClient()
+		defer client.Wait()
+		ctx := cmd.Context()
 
-		result, err := client.QueryEvents(dayForQuery, 1)
+		now := time.Now().In(zone)
+		dayForQuery, err := parseDayArgs(args, now, zone)
 		if err != nil {
 			return err
 		}
 
-		taskIDMap := map[api.ID]api.Task{}
-		for _, t := range result.Tasks {
-			taskIDMap[t.ID] = t
+		result, err := client.QueryEvents(ctx, dayForQuery, 1)
+		if err != nil {
+			return err
 		}
 
-		taskSummary := map[api.ID]time.Duration{}
-
-		ef := api.EventFormatter{DurationFromNow: &now}
-		for _, e := range result.Events {
-			t := taskIDMap[e.TaskID]
-			start := time.Time(e.StartAt)
+		summary := summarizeDay(dayForQuery, now, result)
 
-			if e.EndAt == nil {
-				taskSummary[t.ID] += time.Since(start)
-			} else {
-				end := (*time.Time)(e.EndAt)
-				taskSummary[t.ID] += end.Sub(start)
+		return printResult(summary, func() {
+			events := newTable(table.Column{Right: true}, table.Column{}, table.Column{Right: true}, table.Column{Truncate: true}, table.Column{Truncate: true})
+			for _, e := range summary.Events {
+				events.Row(append(eventCells(&e.Event, now, false), table.Cell{Text: e.TaskName}, table.Cell{Text: csvString(e.Description)})...)
 			}
-			ef.Output(&e)
-			fmt.Printf("\t-- %s\n", taskIDMap[e.TaskID].Name)
-		}
+			events.Flush()
+
+			// then show a summary on time spent
+			fmt.Println()
+			fmt.Println("\t*** Summary ***")
+			tasks := newTable(table.Column{Right: true}, table.Column{Truncate: true}, table.Column{Right: true})
+			for _, t := range summary.Tasks {
+				tasks.Row(table.Cell{Text: strconv.FormatInt(int64(t.ID), 10)}, table.Cell{Text: t.Name},
+					table.Cell{Text: dt.FormatDuration(time.Duration(t.Spent), false)})
+			}
+			tasks.Flush()
+			fmt.Printf("\nTotal Spent: %s\n", dt.FormatDuration(time.Duration(summary.Total), false))
+		})
+	},
+}
+
+// summarizeDay attaches the task names to events, and sums up time spent per task, most spent first
+func summarizeDay(day, now time.Time, result *api.QueryEventsResult) daySummary {
+	taskIDMap := map[api.ID]api.Task{}
+	for _, t := range result.Tasks {
+		taskIDMap[t.ID] = t
+	}
 
-		// then show a summary on time spent
-		fmt.Println()
-		fmt.Println("\t*** Summary ***")
-		sortedK := maps.SortedKByV(taskSummary)
-
-		totalSpent := time.Duration(0)
-		for i := len(sortedK) - 1; i >= 0; i-- {
-			tid := sortedK[i]
-			spent := taskSummary[tid]
-			fmt.Printf("[%d]%s: %s\n", tid, taskIDMap[tid].Name, dt.FormatDuration(spent, false))
-			totalSpent += spent
+	summary := daySummary{Date: dt.ToDate(day), Events: []dayEvent{}, Tasks: []taskSpent{}}
+	taskSummary := map[api.ID]time.Duration{}
+
+	for _, e := range result.Events {
+		t := taskIDMap[e.TaskID]
+		start := time.Time(e.StartAt)
+
+		var spent time.Duration
+		if e.EndAt == nil {
+			spent = now.Sub(start)
+		} else {
+			end := (*time.Time)(e.EndAt)
+			spent = end.Sub(start)
 		}
-		fmt.Printf("\nTotal Spent: %s\n", dt.FormatDuration(totalSpent, false))
+		taskSummary[t.ID] += spent
+		summary.Events = append(summary.Events, dayEvent{Event: e, TaskName: string(t.Name), Spent: seconds(spent)})
+	}
 
-		return nil
-	},
+	sortedK := maps.SortedKByV(taskSummary)
+	for i := len(sortedK) - 1; i >= 0; i-- {
+		tid := sortedK[i]
+		spent := taskSummary[tid]
+		summary.Tasks = append(summary.Tasks, taskSpent{ID: tid, Name: string(taskIDMap[tid].Name), Spent: seconds(spent)})
+		summary.Total += seconds(spent)
+	}
+	return summary
 }
 
 func init() {
+	addCacheFlags(listEventsCommand)
 	rootCmd.AddCommand(listEventsCommand)
 }
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDayArgs(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	// the day after the switch to summer time, 2024-03-31 is 23h long
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, berlin)
+
+	for _, tc := range []struct {
+		args []string
+		want time.Time
+	}{
+		{nil, time.Date(2024, 4, 1, 0, 0, 0, 0, berlin)},
+		{[]string{"p1"}, time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)},
+		{[]string{"p2"}, time.Date(2024, 3, 30, 0, 0, 0, 0, berlin)},
+		{[]string{"2024-03-31"}, time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)},
+	} {
+		got, err := parseDayArgs(tc.args, now, berlin)
+		if err != nil {
+			t.Errorf("parseDayArgs(%q): %v", tc.args, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("parseDayArgs(%q) = %s, want %s", tc.args, got, tc.want)
+		}
+	}
+
+	if _, err := parseDayArgs([]string{"px"}, now, berlin); err == nil {
+		t.Error("parseDayArgs(px) = nil error, want one")
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 	"github.com/todopeer/cli/util/gql"
 )
 
@@ -20,8 +19,8 @@ var gapEventCmd = &cobra.Command{
 	Aliases: []string{"g"},
 	Short:   "add gap (g) to the current running event. It would stop the event, update the endtime to minus the hole size, then resume this same task with a new event",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
 		if len(args) == 0 {
 			return errors.New("Please give the gap size as duration")
@@ -33,7 +32,7 @@ var gapEventCmd = &cobra.Command{
 			return fmt.Errorf("duration parse error: %w", err)
 		}
 
-		event, err := client.QueryRunningEvent()
+		event, err := client.QueryRunningEvent(ctx)
 		if err != nil {
 			return err
 		}
@@ -49,17 +48,18 @@ var gapEventCmd = &cobra.Command{
 			input.Description = gql.ToGqlStringP(args[1])
 		}
 
-		_, err = client.UpdateEvent(event.ID, input)
+		_, err = client.UpdateEvent(ctx, event.ID, input)
 		if err != nil {
 			return err
 		}
 
-		task, _, err := client.StartTask(event.TaskID)
+		task, newEvent, err := client.StartTask(ctx, event.TaskID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("hole added: %s; started new event for task: %s\n", duration, task.Name)
 
-		return nil
+		return printResult(taskEventDoc{Task: task, Event: newEvent}, func() {
+			fmt.Printf("hole added: %s; started new event for task: %s\n", duration, task.Name)
+		})
 	},
 }
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	gqlCmd.Flags().StringVarP(&varGqlFile, "file", "f", "", "read the query document from the file, - for stdin")
+	gqlCmd.Flags().StringArrayVar(&varGqlVars, "var", nil, "variable as key=value. The value is parsed as JSON if valid, otherwise taken as a string")
+	gqlCmd.Flags().StringVar(&varGqlVarsFile, "variables", "", "read variables from the JSON file, - for stdin. --var overrides them")
+	gqlCmd.Flags().StringVar(&varGqlOperation, "operation", "", "the operation to run, if the document has many")
+	rootCmd.AddCommand(gqlCmd)
+}
+
+var gqlCmd = &cobra.Command{
+	Use:   "gql [query]",
+	Short: "send a raw GraphQL query or mutation, and print the JSON response",
+	Long: `The query document is taken from the argument, --file, or stdin if neither is given.
+
+Examples:
+gql '{ me { id email } }'
+gql 'query ($id: ID!) { task(id: $id) { name } }' --var id=12
+gql -f mutation.graphql --variables vars.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := mustGetClient()
+		ctx := cmd.Context()
+
+		query, err := gqlQuery(args)
+		if err != nil {
+			return err
+		}
+		variables, err := gqlVariables()
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Raw(ctx, query, variables, varGqlOperation)
+		if resp == nil {
+			return err
+		}
+
+		printErr := printResult(resp, func() {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			enc.Encode(resp)
+		})
+		if err != nil {
+			return err
+		}
+		return printErr
+	},
+}
+
+func gqlQuery(args []string) (string, error) {
+	switch {
+	case len(args) > 0 && varGqlFile != "":
+		return "", errors.New("query given both as argument & --file")
+	case len(args) > 0 && args[0] != "-":
+		return args[0], nil
+	}
+
+	b, err := readFileOrStdin(varGqlFile)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(b)) == "" {
+		return "", errors.New("empty query document")
+	}
+	return string(b), nil
+}
+
+func gqlVariables() (map[string]any, error) {
+	variables := map[string]any{}
+	if varGqlVarsFile != "" {
+		if varGqlVarsFile == "-" && varGqlFile == "-" {
+			return nil, errors.New("query & variables cannot both come from stdin")
+		}
+
+		b, err := readFileOrStdin(varGqlVarsFile)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, &variables); err != nil {
+			return nil, fmt.Errorf("variables file must be a JSON object: %w", err)
+		}
+	}
+
+	for _, kv := range varGqlVars {
+		key, value, found := strings.Cut(kv, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("expect --var as key=value, got: %s", kv)
+		}
+
+		var v any
+		if json.Unmarshal([]byte(value), &v) != nil {
+			v = value
+		}
+		variables[key] = v
+	}
+	return variables, nil
+}
+
+// readFileOrStdin reads the file, or stdin if the name is - or empty
+func readFileOrStdin(name string) ([]byte, error) {
+	if name == "" || name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
@@ -2,92 +2,211 @@ package commands
 
 import (
 	"bufio"
-	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/todopeer/cli/api"
 	"github.com/todopeer/cli/services/config"
+	"golang.org/x/term"
+)
+
+const (
+	// passwordEnv provides the password for non-interactive login
+	passwordEnv = "TODOPEER_PASSWORD"
+	// passphraseEnv provides the passphrase of the encrypted token, instead of prompting for it
+	passphraseEnv = "TODOPEER_PASSPHRASE"
 )
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Log in to your account",
-	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+	Long: `Prompts for email & password, the password without echo. For non-interactive use, like CI:
+
+login --email me@example.com --password-stdin < password.txt
+TODOPEER_PASSWORD=... login --email me@example.com
+
+Setting TODOPEER_TOKEN instead skips login altogether: all commands use that token, ignoring the stored one.
 
+On shared machines, --encrypt stores the token encrypted with a passphrase. Commands then prompt for the passphrase,
+or take it from TODOPEER_PASSPHRASE.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		token, err := config.ReadToken()
-		client := api.NewClient(token)
+		client, profileErr := newClient(token)
+		if profileErr != nil {
+			return fmt.Errorf("error loading profile: %w", profileErr)
+		}
 		reader := bufio.NewReader(os.Stdin)
 
-		if err != nil {
-			log.Println("Error read token: ", err, "; would do login")
-			token, email, err := doLogin(ctx, reader)
-			if err != nil {
-				log.Fatal("login error: ", err)
-			}
-			config.UpdateToken(token)
-			log.Printf("Logged in as %s successfully!", email)
-		} else {
-			user, err := client.Me()
+		// asked for specific credentials, no need to check the existing login
+		interactive := varLoginEmail == "" && !varLoginPasswordStdin && os.Getenv(passwordEnv) == ""
+		if err == nil && interactive {
+			user, err := client.Me(ctx)
 			if err == nil {
-				log.Println("loaded existing token. User: ", user.Email)
-				fmt.Printf("Login as another user?(Y/%s)", wrapUnderline("N"))
+				notef("loaded existing token. User: %s\n", user.Email)
+				notef("Login as another user?(Y/%s)", wrapUnderline("N"))
 
 				option, err := reader.ReadString('\n')
-				if err != nil {
-					log.Fatal("read input failed with err: ", err)
+				if err != nil && !errors.Is(err, io.EOF) {
+					return fmt.Errorf("read input failed: %w", err)
 				}
-				option = strings.TrimSpace(option)
-				if option != "Y" {
-					return
+				if strings.TrimSpace(option) != "Y" {
+					return nil
 				}
 			} else {
-				log.Println("login using existing token failed: ", err.Error(), "; would proceed to login")
+				notef("login using existing token failed: %s; would proceed to login\n", err)
 			}
+		}
 
-			token, email, err := doLogin(ctx, reader)
-			if err != nil {
-				log.Fatal("login error: ", err)
+		email, password, err := loginCredentials(reader)
+		if err != nil {
+			return err
+		}
+		// asked before login, so a typo doesn't cost the new token
+		var passphrase []byte
+		if varLoginEncrypt {
+			if passphrase, err = newPassphrase(); err != nil {
+				return err
 			}
-			config.UpdateToken(token)
-			log.Printf("Logged in as %s successfully!", email)
 		}
+
+		resp, err := client.Login(ctx, email, password)
+		if err != nil {
+			return fmt.Errorf("login error: %w", err)
+		}
+		if varLoginEncrypt {
+			err = config.UpdateTokenEncrypted(string(resp.Token), passphrase)
+		} else {
+			err = config.UpdateToken(string(resp.Token))
+		}
+		if err != nil {
+			return fmt.Errorf("error saving token: %w", err)
+		}
+
+		notef("Logged in as %s successfully!\n", resp.User.Email)
+		if config.TokenFromEnv() {
+			notef("note: %s is set, and takes precedence over the token just saved\n", config.TokenEnv)
+		}
+		return nil
 	},
 }
 
 func wrapUnderline(s string) string {
+	if !colorEnabled() {
+		return s
+	}
 	return fmt.Sprintf("\x1b[4m\x1b[1m%s\x1b[0m", s)
 }
 
-func doLogin(ctx context.Context, reader *bufio.Reader) (token, email string, err error) {
-	fmt.Print("Enter Email: ")
-	email, err = reader.ReadString('\n')
-	if err != nil {
-		return
+// loginCredentials takes email from --email or the prompt, and password from --password-stdin, TODOPEER_PASSWORD,
+// or the prompt. The password prompt doesn't echo on terminals
+func loginCredentials(reader *bufio.Reader) (email, password string, err error) {
+	email = varLoginEmail
+	if varLoginPasswordStdin && email == "" {
+		return "", "", errors.New("--password-stdin requires --email")
+	}
+	if email == "" {
+		notef("Enter Email: ")
+		if email, err = readLine(reader); err != nil {
+			return "", "", fmt.Errorf("error reading email: %w", err)
+		}
 	}
 
-	fmt.Print("Enter Password: ")
-	password, err := reader.ReadString('\n')
-	if err != nil {
-		return
+	switch {
+	case varLoginPasswordStdin:
+		b, err := io.ReadAll(reader)
+		if err != nil {
+			return "", "", fmt.Errorf("error reading password from stdin: %w", err)
+		}
+		password = strings.TrimRight(string(b), "\r\n")
+	case os.Getenv(passwordEnv) != "":
+		password = os.Getenv(passwordEnv)
+	case isTerminal():
+		if password, err = readSecret("Enter Password: "); err != nil {
+			return "", "", fmt.Errorf("error reading password: %w", err)
+		}
+	default:
+		notef("Enter Password: ")
+		if password, err = readLine(reader); err != nil {
+			return "", "", fmt.Errorf("error reading password: %w", err)
+		}
 	}
 
-	email = strings.TrimSpace(email)
-	password = strings.TrimSpace(password)
+	if email == "" || password == "" {
+		return "", "", errors.New("email & password must not be empty")
+	}
+	return email, password, nil
+}
 
-	resp, err := api.Login(ctx, email, password)
+// readLine reads a line, trimmed. The last line may go without newline
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// readSecret prompts for a secret, not echoing the input
+func readSecret(prompt string) (string, error) {
+	notef("%s", prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	notef("\n")
+	return string(b), err
+}
+
+// readPassphrase provides the passphrase to decrypt the stored token with
+func readPassphrase() ([]byte, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if !isTerminal() {
+		return nil, fmt.Errorf("set %s, or run in a terminal to enter the passphrase", passphraseEnv)
+	}
+
+	passphrase, err := readSecret("Token passphrase: ")
+	return []byte(passphrase), err
+}
+
+// newPassphrase asks for the passphrase to encrypt the token with, twice to avoid typos
+func newPassphrase() ([]byte, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if !isTerminal() {
+		return nil, fmt.Errorf("--encrypt needs %s, or a terminal to enter the passphrase", passphraseEnv)
+	}
+
+	passphrase, err := readSecret("New token passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	confirmed, err := readSecret("Repeat the passphrase: ")
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	if confirmed != passphrase {
+		return nil, errors.New("passphrases don't match")
 	}
-	token = string(resp.Token)
-	email = string(resp.User.Email)
-	return
+	return []byte(passphrase), nil
 }
 
 func init() {
+	config.PassphraseFunc = readPassphrase
+
+	loginCmd.Flags().StringVar(&varLoginEmail, "email", "", "email to log in with, instead of prompting")
+	loginCmd.Flags().BoolVar(&varLoginPasswordStdin, "password-stdin", false, "read the password from stdin, requires --email")
+	loginCmd.Flags().BoolVar(&varLoginEncrypt, "encrypt", false, "store the token encrypted with a passphrase")
 	rootCmd.AddCommand(loginCmd)
 }
@@ -1,10 +1,7 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
-	"github.com/todopeer/cli/api"
 	"github.com/todopeer/cli/services/config"
 )
 
@@ -12,16 +9,21 @@ var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Log out from your account",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
-		err := client.Logout()
+		err := client.Logout(ctx)
 		if err != nil {
 			return err
 		}
 
-		config.UpdateToken("")
-		fmt.Println("Logged out successfully!")
+		// the token from env isn't the stored one, which stays valid
+		if !config.TokenFromEnv() {
+			if err = config.RemoveToken(); err != nil {
+				return err
+			}
+		}
+		notef("Logged out successfully!\n")
 		return nil
 	},
 }
@@ -2,41 +2,48 @@ package commands
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/table"
 )
 
 var meCmd = &cobra.Command{
 	Use:   "my",
 	Short: "show current user & task info",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
-		user, task, event, err := client.MeWithTaskEvent()
+		user, task, event, err := client.MeWithTaskEvent(ctx)
 		if err != nil {
 			return fmt.Errorf("error loading running task: %w", err)
 		}
 
-		if flagSimpleOutput {
-			fmt.Println(task.Name)
-		} else {
+		return printResult(meDoc{User: user, Task: task, Event: event}, func() {
+			if flagSimpleOutput {
+				if task != nil {
+					fmt.Println(task.Name)
+				}
+				return
+			}
+
+			now := time.Now()
 			fmt.Printf("%s - %s\n", user.Name, user.Email)
 			if task != nil {
 				fmt.Println("\tCurrent task: ")
-				task.Output()
+				outputTask(task)
 
 				if event != nil {
 					fmt.Println("\tCurrent event: ")
-					api.EventFormatter{}.Output(event)
+					events := newTable(table.Column{Right: true}, table.Column{}, table.Column{Right: true}, table.Column{Truncate: true})
+					events.Row(append(eventCells(event, now, false), table.Cell{Text: csvString(event.Description)})...)
+					events.Flush()
 				}
 			} else {
 				fmt.Println("no running task")
 			}
-		}
-		return nil
+		})
 	},
 }
 
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/todopeer/cli/util/output"
+	"github.com/todopeer/cli/util/table"
+	"golang.org/x/term"
+)
+
+var (
+	flagOutput string
+	flagFormat string
+	// flagNoColor disables colors, as does a non-empty NO_COLOR env, see https://no-color.org
+	flagNoColor bool
+	// flagAbsoluteTimes shows times as is, instead of relative to now like "due in 3d"
+	flagAbsoluteTimes bool
+
+	printer = &output.Printer{Format: output.FormatText, W: os.Stdout}
+)
+
+func setupPrinter() error {
+	format, err := output.ParseFormat(flagOutput)
+	if err != nil {
+		return err
+	}
+	printer.Format = format
+
+	if flagFormat != "" {
+		printer.Template, err = output.ParseTemplate(flagFormat, zone)
+		if err != nil {
+			return fmt.Errorf("error parsing --format: %w", err)
+		}
+	}
+	return nil
+}
+
+// printResult emits the command result. In text mode, the text func does the printing
+func printResult(doc any, text func()) error {
+	return printer.Print(doc, text)
+}
+
+// streamResult emits a list result item by item, see output.Stream. In text mode, the text func prints each item
+func streamResult(header []string, text func(v any)) *output.Stream {
+	return printer.Stream(header, text)
+}
+
+// notef prints progress messages. They go to stderr when the output is structured, to keep stdout parsable
+func notef(format string, a ...any) {
+	if printer.IsStructured() {
+		fmt.Fprintf(os.Stderr, format, a...)
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// colorEnabled tells whether the text output may use colors: to terminals, unless disabled
+func colorEnabled() bool {
+	return !flagNoColor && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// newTable creates a table for the text output, fit to the terminal width & colored when stdout is a terminal
+func newTable(columns ...table.Column) *table.Table {
+	t := &table.Table{W: os.Stdout, Columns: columns, Color: colorEnabled()}
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		t.MaxWidth = width
+	}
+	return t
+}
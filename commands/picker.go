@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/util/dt"
+	"github.com/todopeer/cli/util/picker"
+	"github.com/todopeer/cli/util/table"
+	"golang.org/x/term"
+)
+
+// pickerHeight is the number of tasks the picker shows at once
+const pickerHeight = 10
+
+// canPick tells whether the user can pick interactively: the picker reads stdin, and draws on stderr
+func canPick() bool {
+	return isTerminal() && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// pickOpenTask lets the user pick one of the open tasks in terminals, for commands given no task while none is running.
+// Otherwise, or with no open task, it fails with fallback
+func pickOpenTask(ctx context.Context, client *api.Client, fallback error) (api.ID, error) {
+	if !canPick() {
+		return 0, fallback
+	}
+
+	input := api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusPaused}}
+	var open []*api.Task
+	it := client.IterateTasks(input, api.DefaultPageSize)
+	for it.Next(ctx) {
+		open = append(open, it.Task())
+	}
+	if err := it.Err(); err != nil {
+		return 0, fmt.Errorf("error loading tasks to pick: %w", err)
+	}
+	if len(open) == 0 {
+		return 0, fallback
+	}
+
+	return runTaskPicker(ctx, client, "no task given, pick one", open)
+}
+
+// runTaskPicker shows the tasks with their status, due date & last activity, the recently worked on first,
+// for the user to pick one
+func runTaskPicker(ctx context.Context, client *api.Client, prompt string, tasks []*api.Task) (api.ID, error) {
+	now := time.Now()
+	activity := recentActivity(ctx, client, now)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return activity[tasks[i].ID].After(activity[tasks[j].ID])
+	})
+
+	maxWidth := 0
+	if width, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && width > 2 {
+		// leave room for the marker of the selected
+		maxWidth = width - 2
+	}
+	items := taskPickerItems(tasks, activity, now, maxWidth)
+
+	i, err := picker.Pick(os.Stdin, os.Stderr, prompt, items, pickerHeight)
+	if errors.Is(err, picker.ErrCanceled) {
+		return 0, errors.New("canceled, no task picked")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error picking task: %w", err)
+	}
+	notef("picked task(id=%d): %s\n", tasks[i].ID, tasks[i].Name)
+	return tasks[i].ID, nil
+}
+
+// taskPickerItems labels each task with its row of the table, rendered on its own so the items follow the tasks
+// whatever the table writes
+func taskPickerItems(tasks []*api.Task, activity map[api.ID]time.Time, now time.Time, maxWidth int) []picker.Item {
+	var buf bytes.Buffer
+	t := &table.Table{
+		W: &buf,
+		Columns: []table.Column{
+			{Width: 4, Right: true},
+			{Width: len(api.TaskStatusNotStarted)},
+			{Width: dueWidth()},
+			{Width: len("worked 23h59m ago")},
+			{Truncate: true},
+		},
+		MaxWidth: maxWidth,
+	}
+
+	items := make([]picker.Item, len(tasks))
+	for i, task := range tasks {
+		cells := taskCells(task, now)
+		last := table.Cell{}
+		if at, found := activity[task.ID]; found {
+			last.Text = "worked " + dt.Relative(at, now)
+			if task.Status == api.TaskStatusDoing && now.Sub(at) < time.Minute {
+				last.Text = "working"
+			}
+		}
+
+		buf.Reset()
+		t.Row(append(cells[:3:3], last, cells[3])...)
+		t.Flush()
+		items[i] = picker.Item{Label: strings.TrimSuffix(buf.String(), "\n")}
+	}
+	return items
+}
+
+// recentActivity gives when each task was last worked on, within the report lookback days. Running events end now.
+// It's best effort, errors leave it empty
+func recentActivity(ctx context.Context, client *api.Client, now time.Time) map[api.ID]time.Time {
+	days := settings.Report.LookbackDays
+	res, err := client.QueryEvents(ctx, now.AddDate(0, 0, -days), days+1)
+	if err != nil {
+		return nil
+	}
+
+	activity := map[api.ID]time.Time{}
+	for _, e := range res.Events {
+		end := now
+		if e.EndAt != nil {
+			end = time.Time(*e.EndAt)
+		}
+		if end.After(activity[e.TaskID]) {
+			activity[e.TaskID] = end
+		}
+	}
+	return activity
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/todopeer/cli/api"
+)
+
+func TestTaskPickerItems(t *testing.T) {
+	now := time.Now()
+	tasks := []*api.Task{
+		{ID: 3, Name: "write\nreport", Status: api.TaskStatusNotStarted},
+		{ID: 12, Name: "a name long enough to be cut at the width of the terminal", Status: api.TaskStatusDoing},
+		{ID: 7, Name: "standup", Status: api.TaskStatusPaused},
+	}
+	activity := map[api.ID]time.Time{12: now, 7: now.Add(-2 * time.Hour)}
+
+	items := taskPickerItems(tasks, activity, now, 60)
+	if len(items) != len(tasks) {
+		t.Fatalf("got %d items, want %d", len(items), len(tasks))
+	}
+	for i, item := range items {
+		id := strings.Fields(item.Label)[0]
+		if want := strings.Fields(string(tasks[i].Name))[0]; id != strconv.FormatInt(int64(tasks[i].ID), 10) || !strings.Contains(item.Label, want) {
+			t.Errorf("item %d = %q, want task %d", i, item.Label, tasks[i].ID)
+		}
+		if strings.Contains(item.Label, "\n") {
+			t.Errorf("item %d = %q, want a single line", i, item.Label)
+		}
+	}
+	if !strings.Contains(items[1].Label, "working") || !strings.Contains(items[2].Label, "worked 2h") {
+		t.Errorf("items = %q, want the last activity", []string{items[1].Label, items[2].Label})
+	}
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os/exec"
@@ -8,14 +10,10 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 var (
 	varContinuePomo bool
-
-	defaultPomoSize  = 25 * time.Minute
-	defaultBreakSize = 5 * time.Minute
 )
 
 const (
@@ -33,9 +31,9 @@ func init() {
 var pomoCmd = &cobra.Command{
 	Use:     "pomodoro",
 	Aliases: []string{"pomo"},
-	Short:   "pomodoro(pomo) [duration]: start a pomodoro with duration. Default to 25m",
+	Short:   "pomodoro(pomo) [duration]: start a pomodoro with duration. Default to 25m, or pomodoro.duration of the config",
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		duration := defaultPomoSize
+		duration := time.Duration(settings.Pomodoro.Duration)
 		if len(args) > 0 {
 			duration, err = time.ParseDuration(args[0])
 			if err != nil {
@@ -47,10 +45,10 @@ var pomoCmd = &cobra.Command{
 		var callback func() error
 
 		if varContinuePomo {
-			token := config.MustGetToken()
-			client := api.NewClient(token)
+			client := mustGetClient()
+			ctx := cmd.Context()
 
-			_, task, event, err := client.MeWithTaskEvent()
+			_, task, event, err := client.MeWithTaskEvent(ctx)
 			if err != nil {
 				return fmt.Errorf("error getting current task: %w", err)
 			}
@@ -58,29 +56,30 @@ var pomoCmd = &cobra.Command{
 				return ErrNoRunningEvent
 			}
 
-			fmt.Printf("continue pomo - task: %s; event start at: %s\n", task.Name, event.StartAt.EventTimeOnly())
+			notef("continue pomo - task: %s; event start at: %s\n", task.Name, event.StartAt.EventTimeOnly(zone))
 			startVal = time.Since((time.Time)(event.StartAt))
 			if startVal > duration {
 				return fmt.Errorf("event is long enough that it should already completed the pomodoro")
 			}
-			callback = makeTaskPauseCallback(client, task.ID)
+			callback = makeTaskPauseCallback(ctx, client, task.ID)
 		}
 
-		return pomodoro(duration, startVal, msgPomoStart, msgPomoDone, callback)
+		return pomodoro(cmd.Context(), duration, startVal, msgPomoStart, msgPomoDone, callback)
 	},
 }
 
-func makeTaskPauseCallback(client *api.Client, taskID api.ID) func() error {
+func makeTaskPauseCallback(ctx context.Context, client *api.Client, taskID api.ID) func() error {
 	return func() error {
-		_, err := client.UpdateTask(taskID, api.TaskUpdateInput{
+		_, err := client.UpdateTask(ctx, taskID, api.TaskUpdateInput{
 			Status: &api.TaskStatusPaused,
 		})
-		if err != nil {
+		if errors.Is(err, api.ErrQueued) {
+			notef("backend unreachable, the pause is queued for sync\n")
+		} else if err != nil {
 			return err
 		}
 
-		pomodoro(defaultBreakSize, 0, msgBreakStart, msgBreakDone, nil)
-		return err
+		return pomodoro(ctx, time.Duration(settings.Pomodoro.Break), 0, msgBreakStart, msgBreakDone, nil)
 	}
 }
 
@@ -96,15 +95,24 @@ func tryToSayWithLog(msg string) {
 	log.Print(msg)
 }
 
-func pomodoro(duration time.Duration, startVal time.Duration, preMessage, doneMessage string, callback func() error) (err error) {
+// pomodoro shows the progress till duration is reached. Returns early with ctx's error if it's done (like Ctrl-C)
+func pomodoro(ctx context.Context, duration time.Duration, startVal time.Duration, preMessage, doneMessage string, callback func() error) (err error) {
 	tryToSayWithLog(preMessage)
 
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
 	start := time.Now().Add(-startVal)
 	for {
-		time.Sleep(time.Second)
+		select {
+		case <-ctx.Done():
+			notef("\n")
+			return ctx.Err()
+		case <-ticker.C:
+		}
 		diff := time.Since(start)
 
-		fmt.Printf("\r%s/%s ", toMMSS(diff), toMMSS(duration))
+		notef("\r%s/%s ", toMMSS(diff), toMMSS(duration))
 		percentage := float32(diff) / float32(duration)
 		if percentage >= 1 {
 			break
@@ -123,15 +131,15 @@ func pomodoro(duration time.Duration, startVal time.Duration, preMessage, doneMe
 
 func showPercentage(percentage float32, size int) {
 	cur := int(percentage * float32(size))
-	fmt.Print("|")
+	notef("|")
 	for i := 0; i < cur; i++ {
-		fmt.Print("*")
+		notef("*")
 	}
 	for cur < size {
-		fmt.Print(" ")
+		notef(" ")
 		cur++
 	}
-	fmt.Print("|")
+	notef("|")
 }
 
 func toMMSS(d time.Duration) string {
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/services/config"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "manage connection profiles, each with its own endpoint & token",
+}
+
+var addProfileCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "add a profile, or update the endpoint of an existing one with -e",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := config.GetProfile(args[0])
+		switch {
+		case errors.Is(err, config.ErrProfileNotFound):
+			p = &config.Profile{Name: args[0]}
+		case err != nil:
+			return err
+		case !cmd.Flags().Changed("endpoint"):
+			// keep the endpoint of the existing profile, instead of resetting it to the default
+			return fmt.Errorf("profile %s already exists, give -e to change its endpoint", p.Name)
+		}
+		p.Endpoint = varProfileEndpoint
+
+		if err = config.SaveProfile(p); err != nil {
+			return err
+		}
+		notef("profile %s saved, endpoint: %s\n", p.Name, profileEndpoint(p))
+
+		if varProfileUse {
+			return useProfile(p.Name)
+		}
+		return nil
+	},
+}
+
+var listProfileCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l"},
+	Short:   "list profiles. The active one is marked with *",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			return err
+		}
+		active, err := config.ActiveProfileName()
+		if err != nil {
+			return err
+		}
+
+		list := make(profileList, len(profiles))
+		for i, p := range profiles {
+			list[i] = profileDoc{Name: p.Name, Endpoint: profileEndpoint(p), Active: p.Name == active, LoggedIn: config.HasToken(p.Name)}
+		}
+
+		return printResult(list, func() {
+			for _, p := range list {
+				mark := " "
+				if p.Active {
+					mark = "*"
+				}
+				loginState := "logged out"
+				if p.LoggedIn {
+					loginState = "logged in"
+				}
+				fmt.Printf("%s %s\t%s\t%s\n", mark, p.Name, p.Endpoint, loginState)
+			}
+		})
+	},
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "set the profile used by default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return useProfile(args[0])
+	},
+}
+
+var removeProfileCmd = &cobra.Command{
+	Use:     "remove [name]",
+	Aliases: []string{"rm"},
+	Short:   "remove a profile and its token",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := config.RemoveProfile(args[0])
+		if errors.Is(err, config.ErrProfileNotFound) {
+			return fmt.Errorf("%w; see `profile list`", err)
+		}
+		if err != nil {
+			return err
+		}
+		notef("profile %s removed\n", args[0])
+		return nil
+	},
+}
+
+type profileDoc struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Active   bool   `json:"active"`
+	LoggedIn bool   `json:"loggedIn"`
+}
+
+type profileList []profileDoc
+
+func (l profileList) Header() []string {
+	return []string{"name", "endpoint", "active", "logged_in"}
+}
+
+func (l profileList) Rows() [][]string {
+	res := make([][]string, len(l))
+	for i, p := range l {
+		res[i] = []string{p.Name, p.Endpoint, strconv.FormatBool(p.Active), strconv.FormatBool(p.LoggedIn)}
+	}
+	return res
+}
+
+func useProfile(name string) error {
+	if err := config.UseProfile(name); err != nil {
+		return err
+	}
+	notef("now using profile: %s\n", name)
+	return nil
+}
+
+func profileEndpoint(p *config.Profile) string {
+	if p.Endpoint == "" {
+		return api.DefaultEndpoint
+	}
+	return p.Endpoint
+}
+
+func init() {
+	addProfileCmd.Flags().StringVarP(&varProfileEndpoint, "endpoint", "e", "", "GraphQL endpoint of the profile, default to "+api.DefaultEndpoint)
+	addProfileCmd.Flags().BoolVarP(&varProfileUse, "use", "u", false, "if set, also make it the active profile")
+
+	profileCmd.AddCommand(addProfileCmd, listProfileCmd, useProfileCmd, removeProfileCmd)
+	rootCmd.AddCommand(profileCmd)
+}
@@ -0,0 +1,339 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/dt"
+	"github.com/todopeer/cli/util/rrule"
+	"github.com/todopeer/cli/util/table"
+)
+
+var recurCmd = &cobra.Command{
+	Use:   "recur",
+	Short: "manage recurring tasks, created on schedule by: recur run",
+	Long: `Recurring tasks are kept locally, per profile. Schedules are iCalendar RRULE-like, with days as occurrences:
+  FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR   every weekday
+  FREQ=WEEKLY;BYDAY=FR              every Friday
+  FREQ=WEEKLY;INTERVAL=2            every other week, on the weekday of the start
+  FREQ=MONTHLY;BYMONTHDAY=1,-1      the first & last day of each month
+Parts are FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL, BYDAY, BYMONTHDAY & UNTIL (like 20231231).
+"recur run" creates the tasks due, e.g. from cron. Each occurrence is created once, however often it runs.`,
+}
+
+var addRecurCmd = &cobra.Command{
+	Use:     "add [name]",
+	Aliases: []string{"a"},
+	Short:   "add a recurring task, like: recur add \"weekly report\" --rule FREQ=WEEKLY;BYDAY=FR",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rule, err := rrule.Parse(varRecurRule)
+		if err != nil {
+			return fmt.Errorf("invalid --rule: %w", err)
+		}
+		start := time.Now().In(zone)
+		if varRecurStart != "" {
+			if start, err = dt.FromDate(varRecurStart, zone); err != nil {
+				return fmt.Errorf("invalid --start, expect a date like 2006-01-02: %s", varRecurStart)
+			}
+		}
+
+		store, err := config.ActiveRecurringStore()
+		if err != nil {
+			return err
+		}
+		unlock, err := store.Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		recurring, err := store.Load()
+		if err != nil {
+			return err
+		}
+
+		r := config.Recurring{ID: 1, Name: args[0], Description: varDescription, Rule: rule.String(), Start: dt.ToDate(start)}
+		for _, existing := range recurring {
+			if existing.ID >= r.ID {
+				r.ID = existing.ID + 1
+			}
+		}
+		if err = store.Save(append(recurring, r)); err != nil {
+			return err
+		}
+
+		doc := recurringDocOf(r)
+		return printResult(doc, func() {
+			fmt.Printf("recurring task(id=%d) added: %s\n", r.ID, r.Name)
+			if doc.Next != "" {
+				fmt.Printf("\tfirst due %s, created by: recur run\n", doc.Next)
+			} else {
+				fmt.Println("\tthe rule never recurs after the start")
+			}
+		})
+	},
+}
+
+var listRecurCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l"},
+	Short:   "list the recurring tasks, with the due date of the next task to create",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.ActiveRecurringStore()
+		if err != nil {
+			return err
+		}
+		recurring, err := store.Load()
+		if err != nil {
+			return err
+		}
+
+		list := make(recurringList, len(recurring))
+		for i, r := range recurring {
+			list[i] = recurringDocOf(r)
+		}
+		return printResult(list, func() {
+			if len(list) == 0 {
+				fmt.Println("no recurring task, add with: recur add")
+				return
+			}
+			t := newTable(table.Column{Right: true}, table.Column{}, table.Column{}, table.Column{Truncate: true})
+			for _, d := range list {
+				next := "next " + d.Next
+				if d.Next == "" {
+					next = "ended"
+				}
+				t.Row(table.Cell{Text: strconv.Itoa(d.ID)}, table.Cell{Text: next}, table.Cell{Text: d.Rule}, table.Cell{Text: d.Name})
+			}
+			t.Flush()
+		})
+	},
+}
+
+var deleteRecurCmd = &cobra.Command{
+	Use:     "delete [id]",
+	Aliases: []string{"rm"},
+	Short:   "delete a recurring task. The tasks created stay",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("recurring task ID parse err: %w", err)
+		}
+
+		store, err := config.ActiveRecurringStore()
+		if err != nil {
+			return err
+		}
+		unlock, err := store.Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		recurring, err := store.Load()
+		if err != nil {
+			return err
+		}
+		for i, r := range recurring {
+			if r.ID != id {
+				continue
+			}
+			if err = store.Save(append(recurring[:i:i], recurring[i+1:]...)); err != nil {
+				return err
+			}
+			return printResult(recurringDocOf(r), func() {
+				fmt.Printf("recurring task(id=%d) deleted: %s\n", r.ID, r.Name)
+			})
+		}
+		return fmt.Errorf("%w: recurring task(id=%d)", api.ErrNotFound, id)
+	},
+}
+
+var runRecurCmd = &cobra.Command{
+	Use:   "run",
+	Short: "create the tasks due of the recurring tasks. Safe to run any time, e.g. from cron",
+	Long: `Creates a task for each occurrence due up to today, or --ahead days later, with its due date.
+Occurrences are created once: each recurring task remembers the last one created, and occurrences with a task of
+the same name due the same day already are skipped. Missed occurrences are created too.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if varRecurAhead < 0 {
+			return errors.New("--ahead must not be negative")
+		}
+		store, err := config.ActiveRecurringStore()
+		if err != nil {
+			return err
+		}
+		var client *api.Client
+		if !varRecurDryRun {
+			// exits if not logged in, so before taking the lock not to leave it behind
+			client = mustGetClient()
+			// held for the whole run, so overlapping runs, like from cron, don't both create the same occurrences
+			unlock, err := store.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+		}
+		recurring, err := store.Load()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		until := time.Now().AddDate(0, 0, varRecurAhead)
+
+		var report recurRunReport
+		for i := range recurring {
+			r := &recurring[i]
+			occurrences, err := dueOccurrences(r, until)
+			if err != nil {
+				report = append(report, recurRunResult{RecurringID: r.ID, Name: r.Name, Error: err.Error()})
+				continue
+			}
+
+			for _, day := range occurrences {
+				res := recurRunResult{RecurringID: r.ID, Name: r.Name, Due: dt.ToDate(day)}
+				if varRecurDryRun {
+					report = append(report, res)
+					continue
+				}
+
+				res.Task, res.Existed, err = createOccurrence(ctx, client, r, day)
+				if err != nil {
+					// the later ones wait for the next run, to keep the order
+					res.Error = err.Error()
+					report = append(report, res)
+					break
+				}
+				// saved each time, so occurrences created are never created again, even if the run stops midway
+				r.Last = res.Due
+				if err = store.Save(recurring); err != nil {
+					return err
+				}
+				report = append(report, res)
+			}
+		}
+
+		var failed int
+		for _, res := range report {
+			if res.Error != "" {
+				failed++
+			}
+		}
+		err = printResult(report, func() {
+			if len(report) == 0 {
+				fmt.Println("nothing due")
+			}
+			for _, res := range report {
+				switch {
+				case res.Error != "":
+					fmt.Printf("failed recurring task(id=%d) %s: %s\n", res.RecurringID, res.Name, res.Error)
+				case varRecurDryRun:
+					fmt.Printf("would create, due %s: %s\n", res.Due, res.Name)
+				case res.Existed:
+					fmt.Printf("exists task(id=%d), due %s: %s\n", res.Task.ID, res.Due, res.Name)
+				default:
+					fmt.Printf("created task(id=%d), due %s: %s\n", res.Task.ID, res.Due, res.Name)
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d recurring task(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// dueOccurrences gives the days of the occurrences of r not created yet, up to until
+func dueOccurrences(r *config.Recurring, until time.Time) ([]time.Time, error) {
+	rule, start, err := parseRecurring(r)
+	if err != nil {
+		return nil, err
+	}
+	return rule.Between(start, lastCreated(r, start), until), nil
+}
+
+// createOccurrence creates the task of the occurrence, unless there's one of the same name due the same day already,
+// e.g. when the run creating it stopped before remembering it. existed tells which one it is
+func createOccurrence(ctx context.Context, client *api.Client, r *config.Recurring, day time.Time) (t *api.Task, existed bool, err error) {
+	due := dt.ToDate(day)
+	after, before := api.Time(day.AddDate(0, 0, -1)), api.Time(day.AddDate(0, 0, 2))
+	// iterated for the filters to be applied locally, by backends not supporting them
+	it := client.IterateTasks(api.QueryTaskInput{
+		Status:     []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusDone, api.TaskStatusPaused},
+		TaskFilter: api.TaskFilter{Search: r.Name, DueAfter: &after, DueBefore: &before},
+	}, 0)
+	for it.Next(ctx) {
+		if t := it.Task(); string(t.Name) == r.Name && t.DueDay() == due {
+			return t, true, nil
+		}
+	}
+	if err = it.Err(); err != nil {
+		return nil, false, fmt.Errorf("error checking for the task due %s: %w", due, err)
+	}
+
+	input := api.TaskCreateInput{Name: graphql.String(r.Name), DueDate: graphql.NewString(graphql.String(due))}
+	if r.Description != "" {
+		input.Description = graphql.NewString(graphql.String(r.Description))
+	}
+	t, err = client.CreateTask(ctx, input)
+	if err != nil {
+		return nil, false, fmt.Errorf("error creating the task due %s: %w", due, err)
+	}
+	return t, false, nil
+}
+
+func parseRecurring(r *config.Recurring) (*rrule.Rule, time.Time, error) {
+	rule, err := rrule.Parse(r.Rule)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid rule: %w", err)
+	}
+	start, err := dt.FromDate(r.Start, zone)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid start: %s", r.Start)
+	}
+	return rule, start, nil
+}
+
+// lastCreated is the day of the last occurrence created, or the day before the start if there's none
+func lastCreated(r *config.Recurring, start time.Time) time.Time {
+	if last, err := dt.FromDate(r.Last, zone); err == nil {
+		return last
+	}
+	return start.AddDate(0, 0, -1)
+}
+
+func recurringDocOf(r config.Recurring) recurringDoc {
+	doc := recurringDoc{Recurring: r}
+	if rule, start, err := parseRecurring(&r); err == nil {
+		if next, found := rule.Next(start, lastCreated(&r, start)); found {
+			doc.Next = dt.ToDate(next)
+		}
+	}
+	return doc
+}
+
+func init() {
+	addRecurCmd.Flags().StringVarP(&varRecurRule, "rule", "r", "", "schedule, like FREQ=WEEKLY;BYDAY=FR. See: recur --help")
+	addRecurCmd.Flags().StringVar(&varRecurStart, "start", "", "first day of the schedule, like 2006-01-02. Default to today")
+	addRecurCmd.Flags().StringVarP(&varDescription, "desc", "d", "", "description of the tasks created")
+	addRecurCmd.MarkFlagRequired("rule")
+
+	runRecurCmd.Flags().IntVar(&varRecurAhead, "ahead", 0, "also create the tasks due in this many days")
+	runRecurCmd.Flags().BoolVar(&varRecurDryRun, "dry-run", false, "list the tasks to create, without creating them")
+
+	recurCmd.AddCommand(addRecurCmd, listRecurCmd, deleteRecurCmd, runRecurCmd)
+	rootCmd.AddCommand(recurCmd)
+}
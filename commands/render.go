@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/util/dt"
+	"github.com/todopeer/cli/util/table"
+)
+
+// the text output of tasks & events, shared by the commands
+
+var statusStyles = map[api.TaskStatus]table.Style{
+	api.TaskStatusDoing:  table.StyleGreen,
+	api.TaskStatusPaused: table.StyleYellow,
+	api.TaskStatusDone:   table.StyleDim,
+}
+
+// newTaskTable creates a table of tasks, by id, status, due date & name. All columns but the name are of fixed width,
+// so the rows are written as they're added
+func newTaskTable() *table.Table {
+	return newTable(
+		table.Column{Width: 4, Right: true},
+		table.Column{Width: len(api.TaskStatusNotStarted)},
+		table.Column{Width: dueWidth()},
+		table.Column{Truncate: true},
+	)
+}
+
+// dueWidth is the width of the due date column, to fit the longest dueText: like "overdue 23h59m", longer than the
+// dates shown with absolute times & for done tasks
+func dueWidth() int {
+	return len("overdue 23h59m")
+}
+
+// taskCells are the cells of the task in task tables. Overdue due dates are in red
+func taskCells(t *api.Task, now time.Time) []table.Cell {
+	due := table.Cell{Text: dueText(t, now)}
+	if t.IsOverdue(now) {
+		due.Style = table.StyleRed
+	}
+	return []table.Cell{
+		{Text: strconv.FormatInt(int64(t.ID), 10)},
+		{Text: string(t.Status), Style: statusStyles[t.Status]},
+		due,
+		{Text: string(t.Name)},
+	}
+}
+
+// dueText is the due date of the task, like "due in 3d" or "overdue 2d". Done tasks, and absolute times, show the
+// day only, like 2006-01-02
+func dueText(t *api.Task, now time.Time) string {
+	switch {
+	case t.DueDate == nil:
+		return ""
+	case flagAbsoluteTimes || t.Status == api.TaskStatusDone:
+		return t.DueDay()
+	case t.IsOverdue(now):
+		return "overdue " + dt.FormatApprox(now.Sub(time.Time(*t.DueDate)))
+	}
+	return "due in " + dt.FormatApprox(time.Time(*t.DueDate).Sub(now))
+}
+
+// eventSpan is the time span of the event, like "14:05 - 15:10", or "started 1h12m ago" while running.
+// withDate adds the day of the start, like "yesterday 14:05 - 15:10"
+func eventSpan(e *api.Event, now time.Time, withDate bool) string {
+	switch {
+	case flagAbsoluteTimes && withDate:
+		return e.StartAt.DateOnly(zone) + " " + e.StartAt.EventTimeOnly(zone) + " - " + e.EndAt.EventTimeOnly(zone)
+	case flagAbsoluteTimes:
+		return e.StartAt.EventTimeOnly(zone) + " - " + e.EndAt.EventTimeOnly(zone)
+	case withDate || e.EndAt == nil:
+		return e.Humanized(now, zone)
+	}
+	return time.Time(e.StartAt).In(zone).Format("15:04") + " - " + time.Time(*e.EndAt).In(zone).Format("15:04")
+}
+
+// eventCells are the id, time span & duration of the event. Running events end now, and are in green
+func eventCells(e *api.Event, now time.Time, withDate bool) []table.Cell {
+	end := now
+	style := table.StyleGreen
+	if e.EndAt != nil {
+		end = time.Time(*e.EndAt)
+		style = table.StyleNone
+	}
+
+	return []table.Cell{
+		{Text: strconv.FormatInt(int64(e.ID), 10)},
+		{Text: eventSpan(e, now, withDate), Style: style},
+		{Text: dt.FormatDuration(end.Sub(time.Time(e.StartAt)), false)},
+	}
+}
+
+// outputTask prints the task as a row of task tables
+func outputTask(t *api.Task) {
+	newTaskTable().Row(taskCells(t, time.Now())...)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/todopeer/cli/api"
+)
+
+// matchTier ranks how well a task name matches the text given for it, higher is better
+type matchTier int
+
+const (
+	noMatch matchTier = iota
+	// the letters of the text appear in the name in order, like "stdp" for "standup". Too loose to pick on its own
+	matchSubsequence
+	// all words of the text appear in the name, in any order
+	matchAllWords
+	matchSubstring
+	matchPrefix
+	matchExact
+)
+
+// resolveTask finds the task the argument refers to: an ID, or a name fuzzy-matched against the open tasks.
+// The single best match is picked. Ties, or loose matches only, are for the user to pick in terminals;
+// otherwise they're an error listing the candidates. No match is api.ErrNotFound
+func resolveTask(ctx context.Context, client *api.Client, arg string) (api.ID, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return api.ID(id), nil
+	}
+	open, err := loadOpenTasks(ctx, client, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	tier, matches := matchTasks(open, arg)
+	switch {
+	case len(matches) == 0:
+		return 0, fmt.Errorf("%w: no open task matches %q", api.ErrNotFound, arg)
+	case len(matches) == 1 && tier > matchSubsequence:
+		notef("matched task(id=%d): %s\n", matches[0].ID, matches[0].Name)
+		return matches[0].ID, nil
+	}
+	return pickTask(ctx, client, arg, matches)
+}
+
+// resolveExactTask is resolveTask taking exact names only, for arguments that may be something else, like a desc.
+// Names matching no open task exactly are api.ErrNotFound
+func resolveExactTask(ctx context.Context, client *api.Client, arg string) (api.ID, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return api.ID(id), nil
+	}
+	open, err := loadOpenTasks(ctx, client, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	tier, matches := matchTasks(open, arg)
+	switch {
+	case tier != matchExact:
+		return 0, fmt.Errorf("%w: no open task named %q", api.ErrNotFound, arg)
+	case len(matches) == 1:
+		return matches[0].ID, nil
+	}
+	return pickTask(ctx, client, arg, matches)
+}
+
+// loadOpenTasks loads the open tasks, to match arg against
+func loadOpenTasks(ctx context.Context, client *api.Client, arg string) ([]*api.Task, error) {
+	input := api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusPaused}}
+	var open []*api.Task
+	it := client.IterateTasks(input, api.DefaultPageSize)
+	for it.Next(ctx) {
+		open = append(open, it.Task())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("error loading tasks to match %q: %w", arg, err)
+	}
+	return open, nil
+}
+
+// matchTasks gives the tasks matching the text best, and how well they match
+func matchTasks(tasks []*api.Task, text string) (matchTier, []*api.Task) {
+	best := noMatch
+	var matches []*api.Task
+	for _, t := range tasks {
+		tier := matchName(string(t.Name), text)
+		switch {
+		case tier == noMatch || tier < best:
+		case tier > best:
+			best, matches = tier, []*api.Task{t}
+		default:
+			matches = append(matches, t)
+		}
+	}
+	return best, matches
+}
+
+func matchName(name, text string) matchTier {
+	name, text = strings.ToLower(strings.TrimSpace(name)), strings.ToLower(strings.TrimSpace(text))
+	switch {
+	case text == "":
+		return noMatch
+	case name == text:
+		return matchExact
+	case strings.HasPrefix(name, text):
+		return matchPrefix
+	case strings.Contains(name, text):
+		return matchSubstring
+	case containsAllWords(name, text):
+		return matchAllWords
+	case isSubsequence(text, name):
+		return matchSubsequence
+	}
+	return noMatch
+}
+
+func containsAllWords(name, text string) bool {
+	for _, word := range strings.Fields(text) {
+		if !strings.Contains(name, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSubsequence(text, name string) bool {
+	rest := []rune(text)
+	for _, r := range name {
+		if len(rest) > 0 && r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// pickTask lets the user pick one of the candidates in terminals, otherwise fails listing them
+func pickTask(ctx context.Context, client *api.Client, arg string, candidates []*api.Task) (api.ID, error) {
+	if canPick() {
+		return runTaskPicker(ctx, client, fmt.Sprintf("%q matches", arg), candidates)
+	}
+
+	names := make([]string, len(candidates))
+	for i, t := range candidates {
+		names[i] = fmt.Sprintf("%d %s", t.ID, t.Name)
+	}
+	if len(candidates) == 1 {
+		return 0, fmt.Errorf("%q only loosely matches %s, give its ID to confirm", arg, names[0])
+	}
+	return 0, fmt.Errorf("%q matches %d tasks, give the ID of one: %s", arg, len(candidates), strings.Join(names, "; "))
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/todopeer/cli/api"
+)
+
+func init() {
+	syncCmd.Flags().BoolVarP(&varSyncList, "list", "l", false, "list the queued changes, without syncing")
+	rootCmd.AddCommand(syncCmd)
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "sync the changes queued while the backend was unreachable",
+	Long: `When the backend is unreachable, start/pause/done get queued locally, with the time they're issued.
+They're replayed in order, back-dated to that time, by the next command reaching the backend, or by sync.
+Changes that no longer apply, e.g. the task got deleted in the meantime, are dropped & reported as conflicts.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := mustGetClient()
+		ctx := cmd.Context()
+
+		if varSyncList {
+			pending, err := client.Pending()
+			if err != nil {
+				return err
+			}
+			return printResult(pendingList(pending), func() {
+				if len(pending) == 0 {
+					fmt.Println("nothing queued")
+				}
+				for i := range pending {
+					fmt.Println(describePending(&pending[i]))
+				}
+			})
+		}
+
+		results, err := client.Replay(ctx)
+		report := syncReportOf(results)
+		printErr := printResult(report, func() {
+			if len(results) == 0 && err == nil {
+				fmt.Println("nothing to sync")
+			}
+			for _, line := range syncLines(report) {
+				fmt.Println(line)
+			}
+		})
+		if err != nil {
+			pending, _ := client.Pending()
+			return fmt.Errorf("sync stopped, %d change(s) still queued: %w", len(pending), err)
+		}
+		return printErr
+	},
+}
+
+func syncReportOf(results []api.ReplayResult) syncReport {
+	report := make(syncReport, len(results))
+	for i, res := range results {
+		report[i] = syncResult{PendingMutation: res.PendingMutation, Task: res.Task, Event: res.Event}
+		if res.Err != nil {
+			report[i].Conflict = res.Err.Error()
+		}
+	}
+	return report
+}
+
+// syncLines describes the replayed changes, one per line
+func syncLines(report syncReport) []string {
+	res := make([]string, len(report))
+	for i := range report {
+		r := &report[i]
+		if r.Conflict != "" {
+			res[i] = fmt.Sprintf("conflict, dropped: %s: %s", describePending(&r.PendingMutation), r.Conflict)
+		} else {
+			res[i] = "synced: " + describePending(&r.PendingMutation)
+		}
+	}
+	return res
+}
+
+// noteSynced reports the queued changes replayed before the command
+func noteSynced(results []api.ReplayResult) {
+	for _, line := range syncLines(syncReportOf(results)) {
+		notef("%s\n", line)
+	}
+}
+
+func describePending(m *api.PendingMutation) string {
+	task := fmt.Sprintf("task(id=%d)", m.TaskID)
+	if m.TaskID == api.CurrentTask {
+		task = "the running task"
+		if m.Kind == api.MutationTaskStart {
+			task = "the latest task"
+		}
+	}
+
+	action := string(m.Kind)
+	switch {
+	case m.Kind == api.MutationTaskStart:
+		action = "start"
+	case m.Input != nil && m.Input.Status != nil:
+		action = "mark " + strings.ToLower(string(*m.Input.Status))
+	case m.Kind == api.MutationTaskUpdate:
+		action = "update"
+	}
+	return fmt.Sprintf("%s %s at %s", action, task, m.At.DateTime(zone))
+}
+
+// printQueued reports the mutation just queued, as the command result
+func printQueued(client *api.Client) error {
+	pending, err := client.Pending()
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	queued := pending[len(pending)-1]
+	return printResult(pendingList{queued}, func() {
+		fmt.Printf("backend unreachable, queued: %s\n", describePending(&queued))
+		fmt.Printf("\tit'll be synced by the next command, or \"todopeer sync\"; %d change(s) queued\n", len(pending))
+	})
+}
@@ -3,26 +3,29 @@ package commands
 import (
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 var deleteTaskCmd = &cobra.Command{
 	Use:     "delete",
 	Aliases: []string{"dt"},
-	Short:   "delete (dt) a task by its ID",
+	Short:   "delete (dt) a task by its ID or name",
+	Long:    `Multiple IDs with --bulk, ranges like 20-25, or --where delete all the tasks.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
+
+		if isBulk(args) {
+			return runBulk(ctx, client, args, bulkOp{verb: "delete", past: "deleted", apply: client.DeleteTask})
+		}
 
 		var taskID api.ID
 		if len(args) == 0 {
-			fmt.Println("taskID not provided, would delete the current running task")
+			notef("taskID not provided, would delete the current running task\n")
 
-			evt, err := client.QueryRunningEvent()
+			evt, err := client.QueryRunningEvent(ctx)
 			if err != nil {
 				return err
 			}
@@ -32,22 +35,23 @@ var deleteTaskCmd = &cobra.Command{
 			}
 			taskID = evt.TaskID
 		} else {
-			taskIDInt, err := strconv.ParseInt(args[0], 10, 64)
-			if err != nil {
+			var err error
+			if taskID, err = resolveTask(ctx, client, args[0]); err != nil {
 				return err
 			}
-			taskID = api.ID(taskIDInt)
 		}
 
-		t, err := client.DeleteTask(taskID)
+		t, err := client.DeleteTask(ctx, taskID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("task(id=%d) deleted successfully: %s\n", t.ID, t.Name)
-		return err
+		return printResult(taskDoc{t}, func() {
+			fmt.Printf("task(id=%d) deleted successfully: %s\n", t.ID, t.Name)
+		})
 	},
 }
 
 func init() {
+	addBulkFlags(deleteTaskCmd, true)
 	rootCmd.AddCommand(deleteTaskCmd)
 }
@@ -1,82 +1,111 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 	"github.com/todopeer/cli/util/gql"
 )
 
 func init() {
+	addBulkFlags(doneTaskCmd, true)
 	rootCmd.AddCommand(doneTaskCmd)
 }
 
-func parseTaskIDAndDesc(args []string) (int64, string, error) {
-	if len(args) == 0 {
+// resolveTaskAndDesc takes the task by ID or name, and the optional desc after it. A single arg is the task only if
+// it's an ID or the exact name of an open task, otherwise it's the desc for the running task
+func resolveTaskAndDesc(ctx context.Context, client *api.Client, args []string) (api.ID, string, error) {
+	switch len(args) {
+	case 0:
 		return 0, "", nil
-	}
-
-	taskIDInt, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		if len(args) == 1 {
+	case 1:
+		// a desc fuzzy-matching some task, or not matched for the backend being unreachable, mustn't finish that task
+		taskID, err := resolveExactTask(ctx, client, args[0])
+		if errors.Is(err, api.ErrNotFound) {
 			return 0, args[0], nil
-		} else {
-			return 0, "", fmt.Errorf("expect arg1 to be id if single argument. Error: %s", err)
 		}
+		return taskID, "", err
 	}
 
-	var desc string
-	if len(args) == 2 {
-		desc = args[1]
+	taskID, err := resolveTask(ctx, client, args[0])
+	if err != nil {
+		return 0, "", err
 	}
-	return taskIDInt, desc, nil
+	return taskID, args[1], nil
 }
 
 var doneTaskCmd = &cobra.Command{
 	Use:     "done",
 	Aliases: []string{"d"},
-	Short:   "done(d) [taskid] [desc] - mark task as done, with optional desc",
-	Long:    `If taskid not provided, use current running task.`,
+	Short:   "done(d) [taskid or name] [desc] - mark task as done, with optional desc",
+	Long: `If taskid not provided, use current running task. Names are matched against the open tasks.
+A single argument that's neither an ID nor the exact name of an open task is the desc for the current running task.
+With no task running, terminals get to pick one of the open tasks.
+Multiple IDs with --bulk, ranges like 20-25, or --where mark all the tasks done, the desc aside.`,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
-		var taskID api.ID
+		if isBulk(args) {
+			return runBulk(ctx, client, args, bulkOp{verb: "complete", past: "done", apply: func(ctx context.Context, taskID api.ID) (*api.Task, error) {
+				return client.UpdateTask(ctx, taskID, api.TaskUpdateInput{Status: &api.TaskStatusDone})
+			}})
+		}
 
-		taskIDInt, desc, err := parseTaskIDAndDesc(args)
+		taskID, desc, err := resolveTaskAndDesc(ctx, client, args)
 		if err != nil {
 			return err
 		}
 
-		if taskIDInt == 0 {
+		input := api.TaskUpdateInput{
+			Status: &api.TaskStatusDone,
+		}
+		// queue together with the description, when the backend is unreachable
+		queue := func(taskID api.ID) error {
+			m := api.PendingMutation{Kind: api.MutationTaskUpdate, TaskID: taskID, At: api.Time(time.Now()), Input: &input}
+			if len(desc) > 0 {
+				m.Description = &desc
+			}
+			if err := client.Queue(m); !errors.Is(err, api.ErrQueued) {
+				return err
+			}
+			return printQueued(client)
+		}
+
+		if taskID == 0 {
 			// try getting the current running task
-			user, err := client.Me()
+			user, err := client.Me(ctx)
+			if errors.Is(err, api.ErrNetwork) {
+				// the running task gets picked when synced
+				return queue(api.CurrentTask)
+			}
 			if err != nil {
 				return err
 			}
 
-			if user.RunningTaskID == nil {
-				return ErrNoRunningTaskNeedID
+			if user.RunningTaskID != nil {
+				taskID = *user.RunningTaskID
+			} else if taskID, err = pickOpenTask(ctx, client, ErrNoRunningTaskNeedID); err != nil {
+				return err
 			}
-			taskID = *user.RunningTaskID
-		} else {
-			taskID = api.ID(taskIDInt)
 		}
 
-		t, err := client.UpdateTask(taskID, api.TaskUpdateInput{
-			Status: &api.TaskStatusDone,
-		})
+		t, err := client.UpdateTask(api.NoQueue(ctx), taskID, input)
+		if errors.Is(err, api.ErrNetwork) {
+			return queue(taskID)
+		}
 		if err != nil {
 			return err
 		}
-		fmt.Printf("task(id=%d) successfully done: %s\n", t.ID, t.Name)
 
+		var event *api.Event
 		if len(desc) > 0 {
 			// use the 2nd arg as input to update the last event attached to this task
-			event, err := client.QueryTaskLastEvent(taskID)
+			event, err = client.QueryTaskLastEvent(ctx, taskID)
 			if err != nil {
 				return err
 			}
@@ -85,13 +114,18 @@ var doneTaskCmd = &cobra.Command{
 				return fmt.Errorf("cannot find event for task(id=%d)", taskID)
 			}
 
-			event, err = client.UpdateEvent(event.ID, api.EventUpdateInput{Description: gql.ToGqlStringP(desc)})
+			eventID := event.ID
+			event, err = client.UpdateEvent(ctx, eventID, api.EventUpdateInput{Description: gql.ToGqlStringP(desc)})
 			if err != nil {
-				return fmt.Errorf("update event(id=%d) error: %w", event.ID, err)
+				return fmt.Errorf("update event(id=%d) error: %w", eventID, err)
 			}
-			fmt.Printf("event(id=%d) updated desc: %s\n", event.ID, *event.Description)
 		}
 
-		return err
+		return printResult(taskEventDoc{Task: t, Event: event}, func() {
+			fmt.Printf("task(id=%d) successfully done: %s\n", t.ID, t.Name)
+			if event != nil {
+				fmt.Printf("event(id=%d) updated desc: %s\n", event.ID, *event.Description)
+			}
+		})
 	},
 }
@@ -2,12 +2,15 @@ package commands
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"regexp"
+	"time"
 
 	"github.com/Shopify/hoff"
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/dt"
 )
 
 var listTaskCmd = &cobra.Command{
@@ -15,9 +18,11 @@ var listTaskCmd = &cobra.Command{
 	Aliases: []string{"l"},
 	Short:   "(l) list tasks",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		defer client.Wait()
+		ctx := cmd.Context()
 
+		applyListSettings(cmd)
 		input := api.QueryTaskInput{}
 		var err error
 		input.Status, err = hoff.MapError(statusForQuery, taskStatusShortToInput)
@@ -26,18 +31,89 @@ var listTaskCmd = &cobra.Command{
 		}
 		log.Printf("loading status: %v", input.Status)
 
-		tasks, err := client.QueryTasks(input)
-		if err != nil {
+		if input.TaskFilter, err = varListFilter.toFilter(); err != nil {
 			return err
 		}
+		sort, found := mapSortFlag2TaskSort[varListSort]
+		if !found {
+			return fmt.Errorf("unknown sort: %s, expect one of id, due, created, updated & name", varListSort)
+		}
+		if varListLimit < 0 {
+			return errors.New("--limit must not be negative")
+		}
+		input.Sort, input.Reverse, input.Limit = sort, varListReverse, varListLimit
 
-		for _, t := range tasks {
-			t.Output()
+		// the tree rolls up the progress of the done subtasks too, so they're loaded even if not listed.
+		// The limit is then of the listed tasks only
+		withDone := varListTree && !hasStatus(input.Status, api.TaskStatusDone)
+		if withDone {
+			input.Status = append(input.Status, api.TaskStatusDone)
+			input.Limit = 0
+		}
+
+		// pages are printed as they come, so long lists (like done tasks) don't wait on being fully loaded
+		now := time.Now()
+		tasks := newTaskTable()
+		// the tree links the tasks to their parents, so it's printed once all are loaded
+		var listed, loaded []*api.Task
+		var count int
+		stream := streamResult(taskHeader, func(v any) {
+			if varListTree {
+				listed = append(listed, v.(taskDoc).Task)
+				return
+			}
+			tasks.Row(taskCells(v.(taskDoc).Task, now)...)
+		})
+		it := client.IterateTasks(input, varPageSize)
+		for it.Next(ctx) {
+			t := it.Task()
+			if varListTree {
+				loaded = append(loaded, t)
+			}
+			if withDone && (t.Status == api.TaskStatusDone || (varListLimit > 0 && count >= varListLimit)) {
+				continue
+			}
+			count++
+			if err = stream.Item(taskDoc{t}); err != nil {
+				return err
+			}
+		}
+		if err = it.Err(); err != nil {
+			return err
 		}
-		return nil
+		if len(listed) > 0 {
+			parents, err := client.QueryTaskParents(ctx, input.Status)
+			if err != nil {
+				return err
+			}
+			outputTaskTree(buildTaskTree(listed, loaded, parents), now)
+		}
+		return stream.Close()
 	},
 }
 
+func hasStatus(statuses []api.TaskStatus, status api.TaskStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// applyListSettings takes the defaults of the flags not given from the settings
+func applyListSettings(cmd *cobra.Command) {
+	if !cmd.Flags().Changed("status") {
+		statusForQuery = settings.List.Status
+	}
+	if !cmd.Flags().Changed("sort") {
+		varListSort = settings.List.Sort
+	}
+	if !cmd.Flags().Changed("page-size") {
+		varPageSize = settings.List.PageSize
+	}
+}
+
 func taskStatusShortToInput(statusShort string, _ int) (api.TaskStatus, error) {
 	r, found := mapStatusShort2TaskStatus[statusShort]
 	if found {
@@ -46,7 +122,73 @@ func taskStatusShortToInput(statusShort string, _ int) (api.TaskStatus, error) {
 	return "", errors.New("unknown status short: " + statusShort)
 }
 
+// listFilterFlags are the flags of list filtering tasks. Times are dates or date times, in local time zone
+type listFilterFlags struct {
+	search, regex               string
+	dueBefore, dueAfter         string
+	createdBefore, createdAfter string
+	updatedBefore, updatedAfter string
+	overdue                     bool
+	eventsBefore, eventsAfter   string
+}
+
+func (f *listFilterFlags) toFilter() (filter api.TaskFilter, err error) {
+	filter.Search, filter.Overdue = f.search, f.overdue
+	if f.regex != "" {
+		// fail early, instead of by the server
+		if _, err = regexp.Compile(f.regex); err != nil {
+			return filter, fmt.Errorf("invalid --regex: %w", err)
+		}
+		filter.Regex = f.regex
+	}
+
+	// due dates are days at midnight UTC, compared as such
+	for _, flag := range []struct {
+		name  string
+		value string
+		dest  **api.Time
+		loc   *time.Location
+	}{
+		{"due-before", f.dueBefore, &filter.DueBefore, time.UTC},
+		{"due-after", f.dueAfter, &filter.DueAfter, time.UTC},
+		{"created-before", f.createdBefore, &filter.CreatedBefore, zone},
+		{"created-after", f.createdAfter, &filter.CreatedAfter, zone},
+		{"updated-before", f.updatedBefore, &filter.UpdatedBefore, zone},
+		{"updated-after", f.updatedAfter, &filter.UpdatedAfter, zone},
+		{"events-before", f.eventsBefore, &filter.EventsBefore, zone},
+		{"events-after", f.eventsAfter, &filter.EventsAfter, zone},
+	} {
+		if flag.value == "" {
+			continue
+		}
+		t, err := dt.FromDateOrTime(flag.value, flag.loc)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --%s, expect a date like 2006-01-02, optionally with time 15:04: %s", flag.name, flag.value)
+		}
+		*flag.dest = (*api.Time)(&t)
+	}
+	return filter, nil
+}
+
 func init() {
-	listTaskCmd.Flags().StringArrayVar(&statusForQuery, "status", []string{"n", "i", "p"}, "n: not_started; i: doing; d: done; p: paused")
+	f := listTaskCmd.Flags()
+	f.StringArrayVar(&statusForQuery, "status", []string{"n", "i", "p"}, "n: not_started; i: doing; d: done; p: paused")
+	f.StringVar(&varListFilter.search, "search", "", "only tasks with the text in name or description, case-insensitive")
+	f.StringVar(&varListFilter.regex, "regex", "", "only tasks with name or description matching the regular expression")
+	f.StringVar(&varListFilter.dueBefore, "due-before", "", "only tasks due before the date")
+	f.StringVar(&varListFilter.dueAfter, "due-after", "", "only tasks due after the date")
+	f.StringVar(&varListFilter.createdBefore, "created-before", "", "only tasks created before the date")
+	f.StringVar(&varListFilter.createdAfter, "created-after", "", "only tasks created after the date")
+	f.StringVar(&varListFilter.updatedBefore, "updated-before", "", "only tasks updated before the date")
+	f.StringVar(&varListFilter.updatedAfter, "updated-after", "", "only tasks updated after the date")
+	f.BoolVar(&varListFilter.overdue, "overdue", false, "only tasks past their due date")
+	f.StringVar(&varListFilter.eventsBefore, "events-before", "", "only tasks with events started before the date")
+	f.StringVar(&varListFilter.eventsAfter, "events-after", "", "only tasks with events started after the date")
+	f.StringVar(&varListSort, "sort", "id", "sort by: id, due, created, updated or name")
+	f.BoolVar(&varListReverse, "reverse", false, "reverse the order")
+	f.IntVar(&varListLimit, "limit", 0, "list up to this many tasks, 0 for all")
+	f.BoolVar(&varListTree, "tree", false, "show subtasks under their parents, with the status rolled up from them. For the text output")
+	f.IntVar(&varPageSize, "page-size", api.DefaultPageSize, "tasks to fetch per request")
+	addCacheFlags(listTaskCmd)
 	rootCmd.AddCommand(listTaskCmd)
 }
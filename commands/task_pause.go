@@ -1,50 +1,70 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 var pauseTaskCmd = &cobra.Command{
 	Use:     "pause",
 	Aliases: []string{"p"},
-	Short:   "pause(p) current running task/event. If an ID is provided, pause that task instead",
+	Short:   "pause(p) current running task/event. If an ID or name is provided, pause that task instead",
+	Long:    `Multiple IDs with --bulk, ranges like 20-25, or --where pause all the tasks.`,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
+
+		input := api.TaskUpdateInput{
+			Status: &api.TaskStatusPaused,
+		}
+		if isBulk(args) {
+			return runBulk(ctx, client, args, bulkOp{verb: "pause", past: "paused", apply: func(ctx context.Context, taskID api.ID) (*api.Task, error) {
+				return client.UpdateTask(ctx, taskID, input)
+			}})
+		}
 
 		var taskID api.ID
 		if len(args) > 0 {
-			taskIDInt, err := strconv.ParseInt(args[0], 10, 64)
-			if err != nil {
-				return fmt.Errorf("unable to parse taskID: %s, error: %w", args[0], err)
+			if taskID, err = resolveTask(ctx, client, args[0]); err != nil {
+				return err
 			}
-			taskID = api.ID(taskIDInt)
 		} else {
 			// try getting the current running task
-			user, err := client.Me()
+			user, err := client.Me(ctx)
+			if errors.Is(err, api.ErrNetwork) {
+				// the running task gets picked when synced
+				err = client.Queue(api.PendingMutation{
+					Kind: api.MutationTaskUpdate, TaskID: api.CurrentTask, At: api.Time(time.Now()), Input: &input,
+				})
+			}
+			if errors.Is(err, api.ErrQueued) {
+				return printQueued(client)
+			}
 			if err != nil {
 				return err
 			}
 
-			if user.RunningTaskID == nil {
-				return ErrNoRunningTaskNeedID
+			if user.RunningTaskID != nil {
+				taskID = *user.RunningTaskID
+			} else if taskID, err = pickOpenTask(ctx, client, ErrNoRunningTaskNeedID); err != nil {
+				return err
 			}
-
-			taskID = *user.RunningTaskID
 		}
 
-		t, err := client.UpdateTask(taskID, api.TaskUpdateInput{
-			Status: &api.TaskStatusPaused,
-		})
+		t, err := client.UpdateTask(ctx, taskID, input)
+		if errors.Is(err, api.ErrQueued) {
+			return printQueued(client)
+		}
 		if err != nil {
 			return err
 		}
-		fmt.Printf("task(id=%d) paused: %s\n", t.ID, t.Name)
-		return err
+		return printResult(taskDoc{t}, func() {
+			fmt.Printf("task(id=%d) paused: %s\n", t.ID, t.Name)
+		})
 	},
 }
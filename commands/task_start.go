@@ -4,17 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strconv"
 	"time"
 
 	"github.com/shurcooL/graphql"
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 func init() {
-	startTaskCmd.Flags().StringVarP(&varDurationOffset, "offset", "o", "", "if provided, start task with offset")
+	startTaskCmd.Flags().StringVarP(&varDurationOffset, "offset", "o", "", "if provided, start task with offset: a duration back from now like 10m, or the time of today like 09:30")
+	startTaskCmd.Flags().BoolVar(&varStartNew, "new", false, "create a new task with the given name, and start it")
 	startTaskCmd.Flags().BoolVarP(&varPomodoro, "pomodoro", "p", false, "if provided, start task with pomodoro")
 	rootCmd.AddCommand(startTaskCmd)
 }
@@ -25,38 +24,54 @@ var startTaskCmd = &cobra.Command{
 	Short:   "(s) start task",
 	Long: `Syntax Supported:
 start: to start the previously running task
-start [task name]: to start a new task with given name
-start [taskID]: to start a new task with given ID
-start [taskID] [Description]: to start a task with given ID, add description to the event
+start [taskID]: to start the task with given ID
+start [task name]: to start the open task matching the name. Ambiguous names ask to pick one
+start [taskID or name] [Description]: to start a task, add description to the event
+start --new [task name]: to start a new task with given name
 
 Examples:
 start "math homework" -p: to start "math homework" task in pomodoro mode
+start --new "math homework": to create "math homework" task, and start it
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
+
+		var offset time.Duration
+		if len(varDurationOffset) > 0 {
+			offset, err = parseStartOffset(varDurationOffset)
+			if err != nil {
+				return fmt.Errorf("error parsing offset: %w", err)
+			}
+		}
 
 		var taskID api.ID
-		var startTaskOptions []api.StartTaskOptionFunc
+		startTaskOptions := []api.StartTaskOptionFunc{api.StartTaskWithOffset(offset)}
 		if len(args) == 0 {
 			// try getting the previously running event
-			e, err := client.QueryLatestEvents()
+			e, err := client.QueryLatestEvents(ctx)
+			if errors.Is(err, api.ErrNetwork) {
+				// the latest task gets picked when synced
+				err = client.Queue(api.PendingMutation{
+					Kind: api.MutationTaskStart, TaskID: api.CurrentTask, At: api.Time(time.Now().Add(-offset)),
+				})
+			}
+			if errors.Is(err, api.ErrQueued) {
+				return printQueued(client)
+			}
 			if err != nil {
 				return fmt.Errorf("query event error: %w", err)
 			}
-			if e == nil {
-				return errors.New("taskID not provided, no event run in recent 2 days")
+			if e != nil {
+				taskID = e.TaskID
+			} else if taskID, err = pickOpenTask(ctx, client,
+				fmt.Errorf("taskID not provided, no event run in recent %d days", settings.Report.LookbackDays)); err != nil {
+				return err
 			}
-			taskID = e.TaskID
 		} else {
-			// if cannot parse as int, then it's task content
-			taskIDInt, err := strconv.ParseInt(args[0], 10, 64)
-			taskID = api.ID(taskIDInt)
-			if err != nil {
-				taskContent := args[0]
-
-				createdTask, err := client.CreateTask(api.TaskCreateInput{
-					Name: graphql.String(taskContent),
+			if varStartNew {
+				createdTask, err := client.CreateTask(ctx, api.TaskCreateInput{
+					Name: graphql.String(args[0]),
 				})
 				if err != nil {
 					return err
@@ -64,37 +79,65 @@ start "math homework" -p: to start "math homework" task in pomodoro mode
 				taskID = createdTask.ID
 				log.Printf("created task with ID: %d", taskID)
 			} else {
-				// if got more string, use it as input to the event desc
-				if len(args) > 1 {
-					startTaskOptions = append(startTaskOptions, api.StartTaskWithDescription(args[1]))
+				taskID, err = resolveTask(ctx, client, args[0])
+				if errors.Is(err, api.ErrNotFound) {
+					return fmt.Errorf("%w; to create it, run: start --new %q", err, args[0])
+				}
+				if err != nil {
+					return err
 				}
 			}
+
+			// if got more string, use it as input to the event desc
+			if len(args) > 1 {
+				startTaskOptions = append(startTaskOptions, api.StartTaskWithDescription(args[1]))
+			}
 		}
-		if len(varDurationOffset) > 0 {
-			offset, err := time.ParseDuration(varDurationOffset)
-			if err != nil {
-				return fmt.Errorf("error parsing offset: %w", err)
+		t, evt, err := client.StartTask(ctx, taskID, startTaskOptions...)
+		if errors.Is(err, api.ErrQueued) {
+			if varPomodoro {
+				notef("pomodoro skipped, as the start is queued\n")
 			}
-			startTaskOptions = append(startTaskOptions, api.StartTaskWithOffset(offset))
+			return printQueued(client)
 		}
-
-		t, evt, err := client.StartTask(taskID, startTaskOptions...)
 		if err != nil {
 			return fmt.Errorf("start task error: %w", err)
 		}
-		fmt.Printf("task(id=%d) started successfully: %s\n", t.ID, t.Name)
-		if evt != nil {
-			fmt.Printf("\tevent(id=%d) started successfully at: %s\n", evt.ID, evt.StartAt.EventTimeOnly())
+		err = printResult(taskEventDoc{Task: t, Event: evt}, func() {
+			fmt.Printf("task(id=%d) started successfully: %s\n", t.ID, t.Name)
+			if evt != nil {
+				fmt.Printf("\tevent(id=%d) started successfully at: %s\n", evt.ID, evt.StartAt.EventTimeOnly(zone))
+			}
+		})
+		if err != nil {
+			return err
 		}
 
 		if varPomodoro {
-			err = pomodoro(defaultPomoSize, 0, msgPomoStart, msgPomoDone, makeTaskPauseCallback(client, t.ID))
+			err = pomodoro(ctx, time.Duration(settings.Pomodoro.Duration), 0, msgPomoStart, msgPomoDone, makeTaskPauseCallback(ctx, client, t.ID))
 
 			if err != nil {
 				return err
 			}
-			fmt.Println("task paused: ", t.Name)
+			notef("task paused: %s\n", t.Name)
 		}
 		return nil
 	},
 }
+
+// parseStartOffset parses --offset: a duration back from now, or the wall-clock time of today in the time zone of --tz
+func parseStartOffset(s string) (time.Duration, error) {
+	if offset, err := time.ParseDuration(s); err == nil {
+		return offset, nil
+	}
+
+	startAt, err := parsePointOfTime(nil, 0, s, zone)
+	if err != nil {
+		return 0, err
+	}
+	offset := time.Since(time.Time(*startAt))
+	if offset < 0 {
+		return 0, fmt.Errorf("start time %s is in the future", s)
+	}
+	return offset, nil
+}
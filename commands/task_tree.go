@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/util/table"
+)
+
+// taskNode is a task with its subtasks, for the tree view of list
+type taskNode struct {
+	*api.Task
+	// children are the listed subtasks, shown under the task
+	children []*taskNode
+	// subtasks are all the subtasks loaded, listed or not, for the rolled-up status & progress
+	subtasks []*taskNode
+}
+
+// buildTaskTree links the listed tasks to their parents, keeping their order. Listed tasks whose parent isn't listed
+// are roots. The other tasks loaded, like done subtasks, only count in the status & progress rolled up
+func buildTaskTree(listed, loaded []*api.Task, parents map[api.ID]api.ID) []*taskNode {
+	nodes := make(map[api.ID]*taskNode, len(loaded))
+	for _, t := range loaded {
+		nodes[t.ID] = &taskNode{Task: t}
+	}
+	for _, t := range loaded {
+		if parent, found := nodes[parents[t.ID]]; found {
+			parent.subtasks = append(parent.subtasks, nodes[t.ID])
+		}
+	}
+
+	isListed := make(map[api.ID]bool, len(listed))
+	for _, t := range listed {
+		isListed[t.ID] = true
+	}
+	var roots []*taskNode
+	for _, t := range listed {
+		n := nodes[t.ID]
+		if parent, found := parents[t.ID]; found && isListed[parent] {
+			nodes[parent].children = append(nodes[parent].children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
+
+// rollUp gives the status of the task with its subtasks: doing if any of them is, done if all are, paused if any
+// is started, otherwise not started. done & total count the subtasks loaded, all levels down
+func (n *taskNode) rollUp() (status api.TaskStatus, done, total int) {
+	doing, started := n.Status == api.TaskStatusDoing, n.Status != api.TaskStatusNotStarted
+	allDone := n.Status == api.TaskStatusDone
+
+	for _, child := range n.subtasks {
+		childStatus, childDone, childTotal := child.rollUp()
+		done, total = done+childDone, total+childTotal+1
+		if child.Status == api.TaskStatusDone {
+			done++
+		}
+
+		doing = doing || childStatus == api.TaskStatusDoing
+		started = started || childStatus != api.TaskStatusNotStarted
+		allDone = allDone && childStatus == api.TaskStatusDone
+	}
+
+	switch {
+	case doing:
+		return api.TaskStatusDoing, done, total
+	case allDone:
+		return api.TaskStatusDone, done, total
+	case started:
+		return api.TaskStatusPaused, done, total
+	}
+	return api.TaskStatusNotStarted, done, total
+}
+
+// outputTaskTree prints the tasks indented under their parents, with the rolled-up status & progress of the subtasks
+func outputTaskTree(roots []*taskNode, now time.Time) {
+	tasks := newTaskTable()
+
+	var walk func(nodes []*taskNode, prefix string, top bool)
+	walk = func(nodes []*taskNode, prefix string, top bool) {
+		for i, n := range nodes {
+			branch, indent := "├─ ", "│  "
+			if i == len(nodes)-1 {
+				branch, indent = "└─ ", "   "
+			}
+			if top {
+				branch, indent = "", ""
+			}
+
+			cells := taskCells(n.Task, now)
+			status, done, total := n.rollUp()
+			cells[1] = table.Cell{Text: string(status), Style: statusStyles[status]}
+			cells[3].Text = prefix + branch + cells[3].Text
+			if total > 0 {
+				cells[3].Text += fmt.Sprintf(" [%d/%d done]", done, total)
+			}
+			tasks.Row(cells...)
+
+			walk(n.children, prefix+indent, false)
+		}
+	}
+	walk(roots, "", true)
+}
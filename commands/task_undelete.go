@@ -7,16 +7,21 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 var undeleteTaskCmd = &cobra.Command{
 	Use:     "undelete",
 	Aliases: []string{"ud"},
 	Short:   "undelete (ud) a task by its ID",
+	Long: `Multiple IDs with --bulk, or ranges like 20-25, undelete all the tasks.
+There's no --where, as deleted tasks can't be queried.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
+
+		if isBulk(args) {
+			return runBulk(ctx, client, args, bulkOp{verb: "undelete", past: "undeleted", apply: client.UndeleteTask})
+		}
 
 		var taskID api.ID
 		if len(args) == 0 {
@@ -29,15 +34,17 @@ var undeleteTaskCmd = &cobra.Command{
 		}
 		taskID = api.ID(taskIDInt)
 
-		t, err := client.UndeleteTask(taskID)
+		t, err := client.UndeleteTask(ctx, taskID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("task(id=%d) undeleted successfully: %s\n", t.ID, t.Name)
-		return err
+		return printResult(taskDoc{t}, func() {
+			fmt.Printf("task(id=%d) undeleted successfully: %s\n", t.ID, t.Name)
+		})
 	},
 }
 
 func init() {
+	addBulkFlags(undeleteTaskCmd, false)
 	rootCmd.AddCommand(undeleteTaskCmd)
 }
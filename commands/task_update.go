@@ -1,15 +1,14 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/shurcooL/graphql"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
 )
 
 func defineFlagsForTaskCUD(s *pflag.FlagSet, isUpdate bool) {
@@ -17,18 +16,23 @@ func defineFlagsForTaskCUD(s *pflag.FlagSet, isUpdate bool) {
 	s.StringVarP(&varDescription, "desc", "d", "", "task description")
 
 	if isUpdate {
+		s.Int64Var(&varParentID, "parent", 0, "move the task under the task of this ID, 0 to make it top-level")
 		s.BoolVarP(&varTriggerPause, "pause", "p", false, "if set, mark as pause")
 		s.StringVarP(&varName, "name", "n", "", "task name")
+	} else {
+		s.Int64Var(&varParentID, "parent", 0, "create as a subtask of the task of this ID")
 	}
 }
 
 func init() {
+	addBulkFlags(pauseTaskCmd, true)
 	rootCmd.AddCommand(pauseTaskCmd)
 
 	defineFlagsForTaskCUD(newTaskCmd.Flags(), false)
 	rootCmd.AddCommand(newTaskCmd)
 
 	defineFlagsForTaskCUD(updateTaskCmd.Flags(), true)
+	addBulkFlags(updateTaskCmd, true)
 	rootCmd.AddCommand(updateTaskCmd)
 }
 
@@ -37,32 +41,13 @@ var updateTaskCmd = &cobra.Command{
 	Aliases: []string{"u"},
 	Short:   "(u)update [taskid] -flags",
 	Long: `Syntax Supported:
-update [taskid]: to update the task with given ID
-update: to update the current running task
+update [taskid or name]: to update the task with given ID, or the open task matching the name
+update: to update the current running task. With none running, terminals get to pick one of the open tasks
+update --bulk [taskid...] or update --where [expr]: to update multiple tasks the same way, by IDs, ranges like 20-25, or filter
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
-
-		var taskID api.ID
-
-		if len(args) == 0 {
-			runningEvent, err := client.QueryRunningEvent()
-			if err != nil {
-				return fmt.Errorf("error querying running event: %w", err)
-			}
-			if runningEvent == nil {
-				return errors.New("no running event")
-			}
-			taskID = runningEvent.TaskID
-		} else {
-			taskIDInt, err := strconv.ParseInt(args[0], 10, 64)
-
-			if err != nil {
-				return fmt.Errorf("error parsing taskID: %w", err)
-			}
-			taskID = api.ID(taskIDInt)
-		}
+		client := mustGetClient()
+		ctx := cmd.Context()
 
 		input := api.TaskUpdateInput{}
 		if varDueDate != "" {
@@ -81,12 +66,45 @@ update: to update the current running task
 			input.Status = &api.TaskStatusNotStarted
 		}
 
-		t, err := client.UpdateTask(api.ID(taskID), input)
+		if cmd.Flags().Changed("parent") {
+			input.ParentID = (*api.ID)(&varParentID)
+		}
+
+		if isBulk(args) {
+			if input == (api.TaskUpdateInput{}) {
+				return errors.New("nothing to update, give the changes as flags")
+			}
+			return runBulk(ctx, client, args, bulkOp{verb: "update", past: "updated", apply: func(ctx context.Context, taskID api.ID) (*api.Task, error) {
+				return client.UpdateTask(ctx, taskID, input)
+			}})
+		}
+
+		var taskID api.ID
+
+		if len(args) == 0 {
+			runningEvent, err := client.QueryRunningEvent(ctx)
+			if err != nil {
+				return fmt.Errorf("error querying running event: %w", err)
+			}
+			if runningEvent != nil {
+				taskID = runningEvent.TaskID
+			} else if taskID, err = pickOpenTask(ctx, client, ErrNoRunningEvent); err != nil {
+				return err
+			}
+		} else {
+			var err error
+			if taskID, err = resolveTask(ctx, client, args[0]); err != nil {
+				return err
+			}
+		}
+
+		t, err := client.UpdateTask(ctx, api.ID(taskID), input)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("task(id=%d) successfully updated: %s\n", t.ID, t.Name)
-		return err
+		return printResult(taskDoc{t}, func() {
+			fmt.Printf("task(id=%d) successfully updated: %s\n", t.ID, t.Name)
+		})
 	},
 }
 
@@ -101,8 +119,8 @@ var newTaskCmd = &cobra.Command{
 			dueTime = (*graphql.String)(&varDueDate)
 		}
 
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		ctx := cmd.Context()
 
 		var desc *string
 
@@ -113,16 +131,20 @@ var newTaskCmd = &cobra.Command{
 			desc = &args[1]
 		}
 
-		task, err := client.CreateTask(api.TaskCreateInput{
+		input := api.TaskCreateInput{
 			Name:        graphql.String(args[0]),
 			Description: (*graphql.String)(desc),
 			DueDate:     dueTime,
-		})
+		}
+		if varParentID != 0 {
+			input.ParentID = (*api.ID)(&varParentID)
+		}
+
+		task, err := client.CreateTask(ctx, input)
 		if err != nil {
 			return err
 		}
 
-		task.Output()
-		return nil
+		return printResult(taskDoc{task}, func() { outputTask(task) })
 	},
 }
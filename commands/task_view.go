@@ -1,55 +1,125 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/todopeer/cli/api"
-	"github.com/todopeer/cli/services/config"
+	"github.com/todopeer/cli/util/dt"
+	"github.com/todopeer/cli/util/table"
 )
 
 var showTaskCmd = &cobra.Command{
 	Use:     "task",
 	Aliases: []string{"t"},
-	Short:   "(t) [id] show task. If not provided, show current running task",
+	Short:   "(t) [id or name] show task. If not provided, show current running task",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token := config.MustGetToken()
-		client := api.NewClient(token)
+		client := mustGetClient()
+		defer client.Wait()
+		ctx := cmd.Context()
 
 		var taskID api.ID
 		if len(args) == 0 {
-			e, err := client.QueryRunningEvent()
+			e, err := client.QueryRunningEvent(ctx)
 			if err != nil {
 				return fmt.Errorf("error query running event: %w", err)
 			}
-			if e == nil {
-				return ErrNoRunningTaskNeedID
+			if e != nil {
+				taskID = e.TaskID
+			} else if taskID, err = pickOpenTask(ctx, client, ErrNoRunningTaskNeedID); err != nil {
+				return err
 			}
-			taskID = e.TaskID
 		} else {
-			taskIDInt, err := strconv.ParseInt(args[0], 10, 64)
-			if err != nil {
+			var err error
+			if taskID, err = resolveTask(ctx, client, args[0]); err != nil {
 				return err
 			}
-			taskID = api.ID(taskIDInt)
 		}
 
-		task, events, err := client.GetTaskEvents(taskID)
+		task, events, err := client.GetTaskEvents(ctx, taskID)
 		if err != nil {
 			return fmt.Errorf("error getting task: %w", err)
 		}
-		task.Output()
-		ef := api.EventFormatter{Prefix: "\t", WithDate: true}
-
-		for _, e := range events {
-			fmt.Printf("\t")
-			ef.Output(&e)
+		now := time.Now()
+		subtasks, err := subtasksSpent(ctx, client, taskID, now)
+		if err != nil {
+			return fmt.Errorf("error getting subtasks: %w", err)
 		}
-		return nil
+
+		return printResult(taskEventsDoc{Task: task, Events: events, Subtasks: subtasks}, func() {
+			outputTask(task)
+
+			eventTable := newTable(table.Column{Right: true}, table.Column{}, table.Column{Right: true}, table.Column{Truncate: true})
+			eventTable.Indent = 4
+			for _, e := range events {
+				eventTable.Row(append(eventCells(&e, now, true), table.Cell{Text: csvString(e.Description)})...)
+			}
+			eventTable.Flush()
+
+			if len(subtasks) == 0 {
+				return
+			}
+			fmt.Println("\n\t*** Subtasks ***")
+			children := newTable(table.Column{Right: true}, table.Column{}, table.Column{Truncate: true}, table.Column{Right: true})
+			var total time.Duration
+			for _, s := range subtasks {
+				children.Row(table.Cell{Text: strconv.FormatInt(int64(s.ID), 10)}, table.Cell{Text: string(s.Status), Style: statusStyles[s.Status]},
+					table.Cell{Text: string(s.Name)}, table.Cell{Text: dt.FormatDuration(time.Duration(s.Spent), false)})
+				total += time.Duration(s.Spent)
+			}
+			children.Flush()
+			fmt.Printf("\nSubtasks Spent: %s\n", dt.FormatDuration(total, false))
+		})
 	},
 }
 
+// subtasksSpent loads the subtasks of the task, with the time tracked on each. The events are loaded in one query,
+// since the day before the first subtask was created, for the ones started back-dated
+func subtasksSpent(ctx context.Context, client *api.Client, taskID api.ID, now time.Time) ([]subtaskSpent, error) {
+	subtasks, err := client.QuerySubtasks(ctx, taskID)
+	if err != nil || len(subtasks) == 0 {
+		return nil, err
+	}
+
+	spent := make(map[api.ID]time.Duration, len(subtasks))
+	since := now
+	for _, t := range subtasks {
+		spent[t.ID] = 0
+		if created := time.Time(t.CreatedAt); created.Before(since) {
+			since = created
+		}
+	}
+	since = since.AddDate(0, 0, -1)
+
+	// a day more, for the events running now
+	days := int(now.Sub(since).Hours()/24) + 2
+	it := client.IterateEvents(since, days, 0)
+	for it.Next(ctx) {
+		e := it.Event()
+		if _, found := spent[e.TaskID]; !found {
+			continue
+		}
+		end := now
+		if e.EndAt != nil {
+			end = time.Time(*e.EndAt)
+		}
+		spent[e.TaskID] += end.Sub(time.Time(e.StartAt))
+	}
+	if err = it.Err(); err != nil {
+		return nil, err
+	}
+
+	res := make([]subtaskSpent, len(subtasks))
+	for i, t := range subtasks {
+		res[i] = subtaskSpent{Task: t, Spent: seconds(spent[t.ID])}
+	}
+	return res, nil
+}
+
 func init() {
+	addCacheFlags(showTaskCmd)
 	rootCmd.AddCommand(showTaskCmd)
 }
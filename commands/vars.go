@@ -11,6 +11,12 @@ import (
 // for TaskList
 var (
 	statusForQuery            []string
+	varPageSize               int
+	varListFilter             listFilterFlags
+	varListSort               string
+	varListReverse            bool
+	varListLimit              int
+	varListTree               bool
 	mapStatusShort2TaskStatus = map[string]api.TaskStatus{
 		"n": api.TaskStatusNotStarted,
 		"i": api.TaskStatusDoing,
@@ -22,6 +28,13 @@ var (
 		"done":        api.TaskStatusDone,
 		"paused":      api.TaskStatusPaused,
 	}
+	mapSortFlag2TaskSort = map[string]api.TaskSort{
+		"id":      "",
+		"due":     api.TaskSortDueDate,
+		"created": api.TaskSortCreatedAt,
+		"updated": api.TaskSortUpdatedAt,
+		"name":    api.TaskSortName,
+	}
 )
 
 // for TaskUpdate
@@ -30,6 +43,23 @@ var (
 	varDescription  string
 	varDueDate      string
 	varTriggerPause bool
+	varParentID     int64
+)
+
+// for acting on tasks in bulk: done, pause, delete, undelete & update
+var (
+	varBulkWhere    string
+	varBulkIDs      bool
+	varBulkYes      bool
+	varBulkParallel int
+)
+
+// for Recur
+var (
+	varRecurRule   string
+	varRecurStart  string
+	varRecurAhead  int
+	varRecurDryRun bool
 )
 
 // for EventUpdate
@@ -52,6 +82,45 @@ var (
 var (
 	varDurationOffset string
 	varPomodoro       bool
+	varStartNew       bool
+)
+
+// for Login
+var (
+	varLoginEmail         string
+	varLoginPasswordStdin bool
+	varLoginEncrypt       bool
+)
+
+// for Profile
+var (
+	varProfileEndpoint string
+	varProfileUse      bool
+)
+
+// for commands reading through the cache
+var (
+	varCached bool
+	varFresh  bool
+)
+
+// for Sync
+var (
+	varSyncList bool
+)
+
+// for Gql
+var (
+	varGqlFile      string
+	varGqlVars      []string
+	varGqlVarsFile  string
+	varGqlOperation string
+)
+
+// for Doctor
+var (
+	varDoctorSchema string
+	varDoctorSave   string
 )
 
 // for common errors
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/util/dt"
+)
+
+var (
+	whereClause = regexp.MustCompile(`^(\w+)\s*(!=|=|<|>|~)\s*(.*)$`)
+	whereAge    = regexp.MustCompile(`^(\d+)([mhdw])$`)
+)
+
+// parseWhere parses --where expressions into the query of the tasks they select, like:
+//
+//	status=paused and updated<30d
+//
+// Clauses are joined by "and", and values with spaces or "and" may be quoted like 'bread and butter':
+//   - status=s or status!=s, with statuses like for list, comma-separated. Default to the open ones: n,i,p
+//   - due, created, updated & events with < or >, to a date, a date time, or an age like 30d (also m, h & w), which
+//     is the time that long before now. So updated<30d is updated more than 30 days ago
+//   - name~text, for the text in name or description
+//   - parent=ID, for the subtasks of the task
+func parseWhere(expr string, now time.Time) (input api.QueryTaskInput, err error) {
+	input.Status = []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusPaused}
+	clauses, err := splitWhere(expr)
+	if err != nil {
+		return input, err
+	}
+	seen := map[string]bool{}
+	for _, clause := range clauses {
+		m := whereClause.FindStringSubmatch(clause)
+		if m == nil {
+			return input, fmt.Errorf("invalid --where clause %q, expect like status=paused", clause)
+		}
+		field, op, value := strings.ToLower(m[1]), m[2], strings.Trim(strings.TrimSpace(m[3]), `'"`)
+		// times may be bounded on both sides
+		key := field
+		if op == "<" || op == ">" {
+			key += op
+		}
+		if seen[key] {
+			return input, fmt.Errorf("--where has %s twice", key)
+		}
+		seen[key] = true
+
+		if err = applyWhereClause(&input, field, op, value, now); err != nil {
+			return input, fmt.Errorf("--where %s: %w", clause, err)
+		}
+	}
+	return input, nil
+}
+
+// splitWhere splits the expression into its clauses, on the words "and" outside quoted values, so values like
+// 'bread and butter' stay whole. Spaces between words outside quotes are kept as one
+func splitWhere(expr string) ([]string, error) {
+	var clauses, words []string
+	var word strings.Builder
+	inWord := false
+	endWord := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		inWord = false
+		if strings.EqualFold(w, "and") {
+			clauses, words = append(clauses, strings.Join(words, " ")), nil
+			return
+		}
+		words = append(words, w)
+	}
+
+	var quote rune
+	for _, r := range expr {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case unicode.IsSpace(r):
+			endWord()
+			continue
+		}
+		word.WriteRune(r)
+		inWord = true
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("invalid --where, unterminated quote %c in: %s", quote, expr)
+	}
+	endWord()
+	return append(clauses, strings.Join(words, " ")), nil
+}
+
+func applyWhereClause(input *api.QueryTaskInput, field, op, value string, now time.Time) error {
+	if value == "" {
+		return errors.New("no value")
+	}
+
+	switch field {
+	case "status":
+		if op != "=" && op != "!=" {
+			return errors.New("expect = or !=")
+		}
+		statuses, err := parseWhereStatuses(value)
+		if err != nil {
+			return err
+		}
+		if op == "!=" {
+			statuses = exceptStatuses(statuses)
+		}
+		input.Status = statuses
+	case "due", "created", "updated", "events":
+		if op != "<" && op != ">" {
+			return errors.New("expect < or >")
+		}
+		// due dates are days at midnight UTC, compared as such
+		loc := zone
+		if field == "due" {
+			loc = time.UTC
+		}
+		t, err := parseWhereTime(value, now, loc)
+		if err != nil {
+			return err
+		}
+		bounds := map[string][2]**api.Time{
+			"due":     {&input.DueBefore, &input.DueAfter},
+			"created": {&input.CreatedBefore, &input.CreatedAfter},
+			"updated": {&input.UpdatedBefore, &input.UpdatedAfter},
+			"events":  {&input.EventsBefore, &input.EventsAfter},
+		}[field]
+		if op == "<" {
+			*bounds[0] = t
+		} else {
+			*bounds[1] = t
+		}
+	case "name":
+		if op != "~" {
+			return errors.New("expect ~")
+		}
+		input.Search = value
+	case "parent":
+		if op != "=" {
+			return errors.New("expect =")
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", value)
+		}
+		input.ParentID = (*api.ID)(&id)
+	default:
+		return fmt.Errorf("unknown field %s, expect one of status, due, created, updated, events, name & parent", field)
+	}
+	return nil
+}
+
+func parseWhereStatuses(value string) ([]api.TaskStatus, error) {
+	var res []api.TaskStatus
+	for _, s := range strings.Split(value, ",") {
+		status, found := mapStatusShort2TaskStatus[strings.ToLower(strings.TrimSpace(s))]
+		if !found {
+			return nil, fmt.Errorf("unknown status %s", s)
+		}
+		res = append(res, status)
+	}
+	return res, nil
+}
+
+// exceptStatuses gives all the statuses but the ones given
+func exceptStatuses(statuses []api.TaskStatus) []api.TaskStatus {
+	var res []api.TaskStatus
+next:
+	for _, s := range []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusDone, api.TaskStatusPaused} {
+		for _, except := range statuses {
+			if s == except {
+				continue next
+			}
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// parseWhereTime parses a date or a date time in the time zone, or an age like 30d for the time that long before now
+func parseWhereTime(value string, now time.Time, loc *time.Location) (*api.Time, error) {
+	if m := whereAge.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[2]]
+		t := api.Time(now.Add(-time.Duration(n) * unit))
+		return &t, nil
+	}
+
+	t, err := dt.FromDateOrTime(value, loc)
+	if err != nil {
+		return nil, fmt.Errorf("expect a date like 2006-01-02, optionally with time 15:04, or an age like 30d: %s", value)
+	}
+	return (*api.Time)(&t), nil
+}
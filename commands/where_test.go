@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/todopeer/cli/api"
+)
+
+func TestSplitWhere(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want []string
+	}{
+		{"status=paused", []string{"status=paused"}},
+		{"status=paused and updated<30d", []string{"status=paused", "updated<30d"}},
+		{"  status = paused   AND  name~x ", []string{"status = paused", "name~x"}},
+		{"name~'bread and butter' and status=n", []string{"name~'bread and butter'", "status=n"}},
+		{`name~"a  b"`, []string{`name~"a  b"`}},
+		{"name~android", []string{"name~android"}},
+	} {
+		got, err := splitWhere(tc.expr)
+		if err != nil {
+			t.Errorf("splitWhere(%q): %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitWhere(%q) = %q, want %q", tc.expr, got, tc.want)
+		}
+	}
+
+	if got, err := splitWhere("name~'bread and butter"); err == nil {
+		t.Errorf("splitWhere with an unterminated quote = %q, want an error", got)
+	}
+}
+
+func TestParseWhere(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *api.Time { return (*api.Time)(&t) }
+	open := []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusPaused}
+	parent := api.ID(42)
+
+	for _, tc := range []struct {
+		expr string
+		want api.QueryTaskInput
+	}{
+		{"name~report", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{Search: "report"}}},
+		{"name~'bread and butter'", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{Search: "bread and butter"}}},
+		{"status=paused", api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusPaused}}},
+		{"status=n,d", api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDone}}},
+		{"status!=done", api.QueryTaskInput{Status: []api.TaskStatus{api.TaskStatusNotStarted, api.TaskStatusDoing, api.TaskStatusPaused}}},
+		{"updated<30d", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{UpdatedBefore: at(now.AddDate(0, 0, -30))}}},
+		{"events>2h", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{EventsAfter: at(now.Add(-2 * time.Hour))}}},
+		{"created>1w", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{CreatedAfter: at(now.AddDate(0, 0, -7))}}},
+		// due dates are days at midnight UTC
+		{"due>2024-01-01 and due<2024-02-01", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{
+			DueAfter:  at(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			DueBefore: at(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+		}}},
+		{"parent=42", api.QueryTaskInput{Status: open, TaskFilter: api.TaskFilter{ParentID: &parent}}},
+	} {
+		got, err := parseWhere(tc.expr, now)
+		if err != nil {
+			t.Errorf("parseWhere(%q): %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseWhere(%q) = %+v, want %+v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParseWhereInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"status",
+		"status=",
+		"status=unknown",
+		"status<n",
+		"owner=me",
+		"due=2024-01-01",
+		"due<tomorrow",
+		"name=x",
+		"parent=x",
+		"parent>1",
+		"status=n and status=p",
+		"name~'x",
+	} {
+		if got, err := parseWhere(expr, time.Now()); err == nil {
+			t.Errorf("parseWhere(%q) = %+v, want an error", expr, got)
+		}
+	}
+}
+
+func TestParseWhereTime(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	for _, tc := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, tokyo)},
+		{"2024-01-02 15:04", time.Date(2024, 1, 2, 15, 4, 0, 0, tokyo)},
+	} {
+		got, err := parseWhereTime(tc.value, now, tokyo)
+		if err != nil {
+			t.Errorf("parseWhereTime(%q): %v", tc.value, err)
+			continue
+		}
+		if !time.Time(*got).Equal(tc.want) {
+			t.Errorf("parseWhereTime(%q) = %s, want %s", tc.value, time.Time(*got), tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path"
+
+	"github.com/todopeer/cli/api"
+)
+
+// FileCache keeps the cached query results of a profile, one file per entry
+type FileCache struct {
+	dir string
+}
+
+var _ api.Cache = (*FileCache)(nil)
+
+func cacheDirFor(profile string) string {
+	return path.Join(Dir(), "cache", profile)
+}
+
+// ActiveCache returns the cache of the active profile
+func ActiveCache() (*FileCache, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: cacheDirFor(profile.Name)}, nil
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	b, err := os.ReadFile(path.Join(c.dir, key+".json"))
+	return b, err == nil
+}
+
+func (c *FileCache) Set(key string, value []byte) error {
+	return writeFileAtomic(path.Join(c.dir, key+".json"), value)
+}
+
+func (c *FileCache) Clear() error {
+	err := os.RemoveAll(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// writeFileAtomic writes to a temp file first, so readers never see the file half written
+func writeFileAtomic(file string, b []byte) error {
+	if err := os.MkdirAll(path.Dir(file), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(path.Dir(file), path.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/todopeer/cli/api"
+)
+
+// FileJournal keeps the pending mutations of a profile as a JSON file
+type FileJournal struct {
+	file string
+}
+
+var _ api.Journal = (*FileJournal)(nil)
+
+func journalFileFor(profile string) string {
+	return path.Join(Dir(), "journal", profile+".json")
+}
+
+// ActiveJournal returns the journal of the active profile
+func ActiveJournal() (*FileJournal, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{file: journalFileFor(profile.Name)}, nil
+}
+
+func (j *FileJournal) Load() ([]api.PendingMutation, error) {
+	b, err := os.ReadFile(j.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []api.PendingMutation
+	if err = json.Unmarshal(b, &pending); err != nil {
+		return nil, fmt.Errorf("corrupted journal file %s: %w", j.file, err)
+	}
+	return pending, nil
+}
+
+func (j *FileJournal) Save(pending []api.PendingMutation) error {
+	if len(pending) == 0 {
+		err := os.Remove(j.file)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	b, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(j.file, b)
+}
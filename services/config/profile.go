@@ -0,0 +1,239 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+)
+
+const (
+	// DefaultProfile is used when no profile is selected
+	DefaultProfile = "default"
+	// EnvProfile is the env var to select a profile, when --profile isn't given
+	EnvProfile = "TODOPEER_PROFILE"
+)
+
+var (
+	profilesFile = path.Join(Dir(), "profiles.json")
+
+	// _selectedProfile overrides env & the persisted current profile, set from the --profile flag
+	_selectedProfile string
+)
+
+var ErrProfileNotFound = errors.New("profile not found")
+
+// profileNameRe keeps names to plain file names, as they're joined into the paths of the token, journal & cache
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func checkProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, _ & - only", name)
+	}
+	return nil
+}
+
+// Profile is a named connection to a backend. Its token is stored separately, see ReadToken
+type Profile struct {
+	Name     string `json:"-"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+type profileStore struct {
+	Current  string              `json:"current,omitempty"`
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Dir returns the config dir of todopeer, following XDG: $XDG_CONFIG_HOME/todopeer, default to ~/.config/todopeer
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return path.Join(dir, "todopeer")
+	}
+	return pathFromHome(".config/todopeer")
+}
+
+func loadProfiles() (*profileStore, error) {
+	store := &profileStore{Profiles: map[string]*Profile{}}
+
+	b, err := os.ReadFile(profilesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, store); err != nil {
+		return nil, fmt.Errorf("corrupted profiles file %s: %w", profilesFile, err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = map[string]*Profile{}
+	}
+	for name, p := range store.Profiles {
+		p.Name = name
+	}
+	return store, nil
+}
+
+func saveProfiles(store *profileStore) error {
+	if err := os.MkdirAll(path.Dir(profilesFile), 0700); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(profilesFile, b, 0600)
+}
+
+// SelectProfile overrides the active profile for this process
+func SelectProfile(name string) {
+	_selectedProfile = name
+	_loaded = false
+}
+
+// ActiveProfileName resolves the profile in use: --profile flag, then $TODOPEER_PROFILE, then `profile use`
+func ActiveProfileName() (string, error) {
+	name, err := activeProfileName()
+	if err != nil {
+		return "", err
+	}
+	return name, checkProfileName(name)
+}
+
+func activeProfileName() (string, error) {
+	if _selectedProfile != "" {
+		return _selectedProfile, nil
+	}
+	if name := os.Getenv(EnvProfile); name != "" {
+		return name, nil
+	}
+
+	store, err := loadProfiles()
+	if err != nil {
+		return "", err
+	}
+	if store.Current != "" {
+		return store.Current, nil
+	}
+	return DefaultProfile, nil
+}
+
+// ActiveProfile returns the profile in use. The default profile always exists
+func ActiveProfile() (*Profile, error) {
+	name, err := ActiveProfileName()
+	if err != nil {
+		return nil, err
+	}
+
+	return GetProfile(name)
+}
+
+func GetProfile(name string) (*Profile, error) {
+	store, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	p, found := store.Profiles[name]
+	if !found {
+		if name == DefaultProfile {
+			return &Profile{Name: DefaultProfile}, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return p, nil
+}
+
+// ListProfiles returns all profiles sorted by name, including the default one
+func ListProfiles() ([]*Profile, error) {
+	store, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, found := store.Profiles[DefaultProfile]; !found {
+		store.Profiles[DefaultProfile] = &Profile{Name: DefaultProfile}
+	}
+
+	res := make([]*Profile, 0, len(store.Profiles))
+	for _, p := range store.Profiles {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// SaveProfile adds the profile, or updates its endpoint if it already exists
+func SaveProfile(p *Profile) error {
+	if err := checkProfileName(p.Name); err != nil {
+		return err
+	}
+
+	store, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	store.Profiles[p.Name] = p
+	return saveProfiles(store)
+}
+
+// UseProfile persists the profile as the current one
+func UseProfile(name string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+
+	store, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	if _, found := store.Profiles[name]; !found && name != DefaultProfile {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	store.Current = name
+	return saveProfiles(store)
+}
+
+// RemoveProfile deletes the profile together with its token, pending mutations, recurring tasks & cache
+func RemoveProfile(name string) error {
+	if name == DefaultProfile {
+		return errors.New("the default profile cannot be removed")
+	}
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+
+	store, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	if _, found := store.Profiles[name]; !found {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	delete(store.Profiles, name)
+	if store.Current == name {
+		store.Current = ""
+	}
+
+	if err = removeSecurely(tokenFileFor(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, file := range []string{journalFileFor(name), recurringFileFor(name), cacheDirFor(name)} {
+		err = os.RemoveAll(file)
+		if err != nil {
+			return err
+		}
+	}
+	return saveProfiles(store)
+}
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestProfileNames(t *testing.T) {
+	for _, name := range []string{"default", "work", "staging-2", "my_profile"} {
+		if err := checkProfileName(name); err != nil {
+			t.Errorf("checkProfileName(%q): %v", name, err)
+		}
+	}
+
+	// names are joined into paths under the config dir, so none may reach out of it
+	for _, name := range []string{"", "../x", "a/b", ".", "..", "a b", `a\b`} {
+		if err := SaveProfile(&Profile{Name: name}); err == nil {
+			t.Errorf("SaveProfile(%q) succeeded, want an error", name)
+		}
+		if err := UseProfile(name); err == nil {
+			t.Errorf("UseProfile(%q) succeeded, want an error", name)
+		}
+		if err := RemoveProfile(name); err == nil {
+			t.Errorf("RemoveProfile(%q) succeeded, want an error", name)
+		}
+	}
+
+	SelectProfile("../x")
+	defer SelectProfile("")
+	if _, err := ActiveProfileName(); err == nil {
+		t.Error("ActiveProfileName with --profile ../x succeeded, want an error")
+	}
+}
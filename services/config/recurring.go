@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// ErrRecurringLocked is returned by RecurringStore.Lock while another command holds the lock
+var ErrRecurringLocked = errors.New("recurring tasks are in use by another command, like a recur run")
+
+// staleLockAge is how old a lock file is taken as left behind by a command that crashed
+const staleLockAge = time.Hour
+
+// Recurring is a task to create again & again on a schedule, by "recur run"
+type Recurring struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Rule is the schedule, like FREQ=WEEKLY;BYDAY=FR, see rrule.Parse
+	Rule string `json:"rule"`
+	// Start is the first day of the schedule, like 2006-01-02
+	Start string `json:"start"`
+	// Last is the due date of the latest task created, like 2006-01-02. Runs only create the ones due after it
+	Last string `json:"last,omitempty"`
+}
+
+// RecurringStore keeps the recurring tasks of a profile as a JSON file. They're local, the backend knows nothing of them
+type RecurringStore struct {
+	file string
+}
+
+func recurringFileFor(profile string) string {
+	return path.Join(Dir(), "recurring", profile+".json")
+}
+
+// ActiveRecurringStore returns the recurring tasks store of the active profile
+func ActiveRecurringStore() (*RecurringStore, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	return &RecurringStore{file: recurringFileFor(profile.Name)}, nil
+}
+
+func (s *RecurringStore) Load() ([]Recurring, error) {
+	b, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recurring []Recurring
+	if err = json.Unmarshal(b, &recurring); err != nil {
+		return nil, fmt.Errorf("corrupted recurring tasks file %s: %w", s.file, err)
+	}
+	return recurring, nil
+}
+
+// Lock takes the lock file next to the store, so commands changing it don't overlap, like runs from cron. It fails
+// with ErrRecurringLocked while another one holds it, unless that's older than staleLockAge. unlock releases it
+func (s *RecurringStore) Lock() (unlock func() error, err error) {
+	lock := s.file + ".lock"
+	if err = os.MkdirAll(path.Dir(lock), 0700); err != nil {
+		return nil, err
+	}
+
+	for takenOver := false; ; takenOver = true {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lock)
+				return nil, err
+			}
+			return func() error { return os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		info, err := os.Stat(lock)
+		if err == nil && (takenOver || time.Since(info.ModTime()) < staleLockAge) {
+			return nil, fmt.Errorf("%w; if none is, remove the lock file %s", ErrRecurringLocked, lock)
+		}
+		if err == nil {
+			err = os.Remove(lock)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+}
+
+func (s *RecurringStore) Save(recurring []Recurring) error {
+	b, err := json.MarshalIndent(recurring, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.file, b)
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedPrefix marks token files encrypted with a passphrase
+const encryptedPrefix = "encrypted:v1:"
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase, or the token file is corrupted")
+
+// PassphraseFunc provides the passphrase to decrypt the stored token with. The commands set it, to prompt for it
+var PassphraseFunc func() ([]byte, error)
+
+func isEncrypted(token string) bool {
+	return strings.HasPrefix(token, encryptedPrefix)
+}
+
+// encryptToken encrypts with AES-GCM, under a key derived from the passphrase by scrypt
+func encryptToken(token string, passphrase []byte) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := tokenCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	// salt | nonce | ciphertext
+	sealed := aead.Seal(append(salt, nonce...), nonce, []byte(token), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptToken(content string) (string, error) {
+	if PassphraseFunc == nil {
+		return "", errors.New("the token is encrypted, but no passphrase is provided")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(content, encryptedPrefix))
+	if err != nil || len(sealed) < saltSize {
+		return "", ErrWrongPassphrase
+	}
+	passphrase, err := PassphraseFunc()
+	if err != nil {
+		return "", fmt.Errorf("the token is encrypted: %w", err)
+	}
+
+	aead, err := tokenCipher(passphrase, sealed[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	sealed = sealed[saltSize:]
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrWrongPassphrase
+	}
+
+	token, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(token), nil
+}
+
+func tokenCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownSetting = errors.New("unknown setting")
+
+// Settings are the user preferences & command defaults, kept in SettingsFile. Flags override them
+type Settings struct {
+	Pomodoro PomodoroSettings `yaml:"pomodoro"`
+	List     ListSettings     `yaml:"list"`
+	Report   ReportSettings   `yaml:"report"`
+	// TimeZone is an IANA name like Europe/Berlin. Empty for the system's
+	TimeZone string         `yaml:"time_zone"`
+	Output   OutputSettings `yaml:"output"`
+}
+
+type PomodoroSettings struct {
+	Duration Duration `yaml:"duration"`
+	Break    Duration `yaml:"break"`
+}
+
+// ListSettings are the defaults of the list command
+type ListSettings struct {
+	Status   []string `yaml:"status"`
+	Sort     string   `yaml:"sort"`
+	PageSize int      `yaml:"page_size"`
+}
+
+type ReportSettings struct {
+	// LookbackDays is how far back to find the latest event, for commands picking the last task worked on
+	LookbackDays int `yaml:"lookback_days"`
+}
+
+type OutputSettings struct {
+	Format string `yaml:"format"`
+}
+
+// Duration is a time.Duration written like 25m in the settings
+type Duration time.Duration
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	v, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// DefaultSettings is what applies without the settings file
+func DefaultSettings() *Settings {
+	return &Settings{
+		Pomodoro: PomodoroSettings{Duration: Duration(25 * time.Minute), Break: Duration(5 * time.Minute)},
+		List:     ListSettings{Status: []string{"n", "i", "p"}, Sort: "id", PageSize: 100},
+		Report:   ReportSettings{LookbackDays: 2},
+		Output:   OutputSettings{Format: "text"},
+	}
+}
+
+// SettingsFile is where the settings are kept
+func SettingsFile() string {
+	return path.Join(Dir(), "config.yaml")
+}
+
+// LoadSettings reads the settings file, on top of the defaults
+func LoadSettings() (*Settings, error) {
+	s := DefaultSettings()
+
+	b, err := os.ReadFile(SettingsFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", SettingsFile(), err)
+	}
+	return s, nil
+}
+
+func SaveSettings(s *Settings) error {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(SettingsFile(), b)
+}
+
+// SettingKeys lists the keys of all settings, like pomodoro.duration
+func SettingKeys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key := prefix + settingName(f)
+			if f.Type.Kind() == reflect.Struct {
+				walk(f.Type, key+".")
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	walk(reflect.TypeOf(Settings{}), "")
+	return keys
+}
+
+// Get gives the setting by key, formatted like Set takes it
+func (s *Settings) Get(key string) (string, error) {
+	v, err := s.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := v.Interface().(type) {
+	case Duration:
+		return time.Duration(v).String(), nil
+	case []string:
+		return strings.Join(v, ","), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("setting %s of unsupported type %s", key, v.Type())
+}
+
+// Set parses the value into the setting. Lists are comma-separated, durations like 25m
+func (s *Settings) Set(key, value string) error {
+	v, err := s.field(key)
+	if err != nil {
+		return err
+	}
+
+	switch v.Interface().(type) {
+	case Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s expects a duration like 25m: %w", key, err)
+		}
+		v.Set(reflect.ValueOf(Duration(d)))
+	case []string:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s expects a number: %w", key, err)
+		}
+		v.SetInt(int64(n))
+	case string:
+		v.SetString(value)
+	default:
+		return fmt.Errorf("setting %s of unsupported type %s", key, v.Type())
+	}
+	return nil
+}
+
+// field finds the field of the dotted key, by the yaml names
+func (s *Settings) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(s).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+		}
+
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if settingName(v.Type().Field(i)) == name {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+		}
+	}
+	if v.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: %s is a section, not a setting", ErrUnknownSetting, key)
+	}
+	return v, nil
+}
+
+func settingName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	return name
+}
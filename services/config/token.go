@@ -1,16 +1,23 @@
 package config
 
 import (
-	"bufio"
+	"errors"
+	"io"
 	"log"
 	"os"
 	"path"
+	"strings"
 )
 
+// TokenEnv overrides the stored token of the active profile, for ephemeral environments like CI
+const TokenEnv = "TODOPEER_TOKEN"
+
 var (
-	tokenFile = pathFromHome(".diarier_token.txt")
-	_token    string
-	_loaded   bool
+	// legacyTokenFile is where the default profile's token used to be. It's moved into Dir on first access
+	legacyTokenFile = pathFromHome(".diarier_token.txt")
+	_token          string
+	_loaded         bool
+	_migrated       bool
 )
 
 // pathFromHome appends the home dir, in front of the given relativePath
@@ -19,46 +26,185 @@ func pathFromHome(relativePath string) string {
 	return path.Join(home, relativePath)
 }
 
+// tokenFileFor gives the token file of the profile
+func tokenFileFor(profile string) string {
+	if profile == DefaultProfile {
+		migrateLegacyToken()
+	}
+	return path.Join(Dir(), "tokens", profile)
+}
+
+// migrateLegacyToken moves the token file of older versions into Dir, unless the default profile has a token there
+func migrateLegacyToken() {
+	if _migrated {
+		return
+	}
+	_migrated = true
+
+	b, err := os.ReadFile(legacyTokenFile)
+	if err != nil {
+		return
+	}
+	tokenFile := path.Join(Dir(), "tokens", DefaultProfile)
+	if _, err = os.Stat(tokenFile); errors.Is(err, os.ErrNotExist) {
+		if err = writeFileAtomic(tokenFile, b); err != nil {
+			log.Printf("migrating token file %s: %v", legacyTokenFile, err)
+			return
+		}
+	}
+	if err = removeSecurely(legacyTokenFile); err != nil {
+		log.Printf("removing legacy token file %s: %v", legacyTokenFile, err)
+	}
+}
+
+func activeTokenFile() (string, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	return tokenFileFor(profile.Name), nil
+}
+
+// TokenFromEnv tells whether the token is taken from TokenEnv, instead of the token file
+func TokenFromEnv() bool {
+	return os.Getenv(TokenEnv) != ""
+}
+
+// ReadToken loads the token of the active profile, or the one from TokenEnv if set.
+// Encrypted tokens are decrypted with the passphrase from PassphraseFunc
 func ReadToken() (string, error) {
+	if token := os.Getenv(TokenEnv); token != "" {
+		return token, nil
+	}
 	if _loaded {
 		return _token, nil
 	}
 
+	tokenFile, err := activeTokenFile()
+	if err != nil {
+		return "", err
+	}
+
 	file, err := os.Open(tokenFile)
-	_loaded = true
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	scanner.Scan()
-	_token = scanner.Text()
-	return _token, scanner.Err()
+	// tighten the files written by older versions, with default permissions
+	if info, err := file.Stat(); err == nil && info.Mode().Perm()&0077 != 0 {
+		if err = file.Chmod(0600); err != nil {
+			log.Printf("restricting permissions of %s: %v", tokenFile, err)
+		}
+	}
+
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	token, _, _ := strings.Cut(string(b), "\n")
+	if isEncrypted(token) {
+		if token, err = decryptToken(token); err != nil {
+			return "", err
+		}
+	}
+
+	// cached only once read & decrypted, so failures are retried & reported again
+	_token, _loaded = token, true
+	return _token, nil
 }
 
 func MustGetToken() string {
 	token, err := ReadToken()
-	if err != nil {
+	if errors.Is(err, os.ErrNotExist) {
 		log.Fatal("Please login first. Error when loading token: ", err)
 	}
+	if err != nil {
+		log.Fatal("Error when loading token: ", err)
+	}
 	return token
 }
 
+// HasToken tells whether the profile has a token stored
+func HasToken(profile string) bool {
+	info, err := os.Stat(tokenFileFor(profile))
+	return err == nil && info.Size() > 0
+}
+
+// UpdateToken stores the token for the active profile, readable by the user only
 func UpdateToken(token string) error {
-	file, err := os.Create(tokenFile)
+	return saveToken(token, token)
+}
+
+// UpdateTokenEncrypted stores the token for the active profile, encrypted with the passphrase
+func UpdateTokenEncrypted(token string, passphrase []byte) error {
+	encrypted, err := encryptToken(token, passphrase)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return saveToken(token, encrypted)
+}
 
-	_, err = file.WriteString(token)
+func saveToken(token, content string) error {
+	tokenFile, err := activeTokenFile()
 	if err != nil {
 		return err
 	}
 
+	if err = writeFileAtomic(tokenFile, []byte(content)); err != nil {
+		return err
+	}
+
 	_token = token
 	_loaded = true
 
 	return nil
 }
+
+// RemoveToken deletes the token of the active profile, overwriting it first
+func RemoveToken() error {
+	tokenFile, err := activeTokenFile()
+	if err != nil {
+		return err
+	}
+
+	if err = removeSecurely(tokenFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	_token = ""
+	_loaded = false
+	return nil
+}
+
+// removeSecurely overwrites the file with zeros before removing it, so the content doesn't linger on disk.
+// It's best effort: copy-on-write filesystems & SSDs may still keep the old blocks
+func removeSecurely(file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err == nil {
+		if _, err = io.Copy(f, io.LimitReader(zeros{}, info.Size())); err == nil {
+			err = f.Sync()
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(file)
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,59 @@
+package dt
+
+import (
+	"strconv"
+	"time"
+)
+
+// FormatApprox formats the duration coarsely, for reading at a glance: days from 1 day on, like 3d, otherwise like 1h12m
+func FormatApprox(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d >= 24*time.Hour:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	case d < time.Minute:
+		return "<1m"
+	}
+	return FormatDuration(d, false)
+}
+
+// Relative formats the time relative to now, like "in 3d" or "1h12m ago". Under a minute apart is "just now"
+func Relative(t, now time.Time) string {
+	d := t.Sub(now)
+	switch {
+	case d > -time.Minute && d < time.Minute:
+		return "just now"
+	case d > 0:
+		return "in " + FormatApprox(d)
+	}
+	return FormatApprox(d) + " ago"
+}
+
+// Day formats the time with its day relative to now's: "today 14:05", "yesterday 14:05" or "tomorrow 14:05", then
+// the weekday within a week like "Mon 14:05", and the date beyond. In the time zone
+func Day(t, now time.Time, loc *time.Location) string {
+	t, now = t.In(loc), now.In(loc)
+	clock := t.Format("15:04")
+
+	days := DaysBetween(now, t)
+	switch {
+	case days == 0:
+		return "today " + clock
+	case days == -1:
+		return "yesterday " + clock
+	case days == 1:
+		return "tomorrow " + clock
+	case days > -7 && days < 7:
+		return t.Format("Mon ") + clock
+	}
+	return t.Format("2006-01-02 ") + clock
+}
+
+// DaysBetween counts the calendar days from a to b, by their dates as is, negative if b is before a
+func DaysBetween(a, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dayB.Sub(dayA) / (24 * time.Hour))
+}
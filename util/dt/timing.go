@@ -9,8 +9,19 @@ func FromTime(timeStr string) (time.Time, error) {
 	return time.Parse(time.RFC3339Nano, timeStr)
 }
 
-func FromDate(dateStr string) (time.Time, error) {
-	return time.Parse(time.DateOnly, dateStr)
+// FromDate parses a date as its start in the time zone, so day windows follow the displayed one
+func FromDate(dateStr string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, dateStr, loc)
+}
+
+// FromDateOrTime parses a date (as its start) or date & time in the time zone, or an RFC3339 time
+func FromDateOrTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.DateTime, "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Parse(time.RFC3339Nano, s)
 }
 
 func ToDate(t time.Time) string {
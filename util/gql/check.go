@@ -0,0 +1,319 @@
+package gql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is an incompatibility of an operation with the schema. Path is the response path within the operation
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Path, i.Message)
+}
+
+// Check statically checks the operation against the schema: fields & arguments exist, required arguments are given,
+// variables fit the arguments, and leaf fields have no selections while others have. Deprecated usages are warnings
+func Check(schema *Schema, doc *Document, op *Operation) []Issue {
+	c := &checker{schema: schema, doc: doc, vars: map[string]*TypeRef{}}
+
+	root := schema.RootType(op.Type)
+	if root == nil {
+		c.errorf("", "schema has no %s type", op.Type)
+		return c.issues
+	}
+
+	for _, def := range op.Variables {
+		c.vars[def.Name] = def.Type
+		t := schema.Types[def.Type.NamedType()]
+		switch {
+		case t == nil:
+			c.errorf("$"+def.Name, "unknown type %s", def.Type.NamedType())
+		case t.Kind != KindScalar && t.Kind != KindEnum && t.Kind != KindInputObject:
+			c.errorf("$"+def.Name, "%s is not an input type", t.Name)
+		}
+	}
+
+	c.selectionSet(op.SelectionSet, root, "")
+	return c.issues
+}
+
+type checker struct {
+	schema *Schema
+	doc    *Document
+	vars   map[string]*TypeRef
+	issues []Issue
+}
+
+func (c *checker) errorf(path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Severity: SeverityError, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warnf(path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Severity: SeverityWarning, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) selectionSet(sels []Selection, t *TypeDef, path string) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *Field:
+			c.field(sel, t, joinPath(path, sel.ResponseKey()))
+		case *InlineFragment:
+			c.selectionSet(sel.SelectionSet, c.typeCondition(sel.TypeCondition, t, path), path)
+		case *FragmentSpread:
+			f, found := c.doc.Fragments[sel.Name]
+			if !found {
+				c.errorf(path, "unknown fragment %s", sel.Name)
+				continue
+			}
+			c.selectionSet(f.SelectionSet, c.typeCondition(f.TypeCondition, t, path), path)
+		}
+	}
+}
+
+func (c *checker) typeCondition(name string, t *TypeDef, path string) *TypeDef {
+	if name == "" {
+		return t
+	}
+	if cond := c.schema.Types[name]; cond != nil {
+		return cond
+	}
+	c.errorf(path, "unknown type %s", name)
+	return t
+}
+
+func (c *checker) field(f *Field, t *TypeDef, path string) {
+	if f.Name == "__typename" {
+		return
+	}
+
+	def := t.Field(f.Name)
+	if def == nil {
+		names := make([]string, len(t.Fields))
+		for i, field := range t.Fields {
+			names[i] = field.Name
+		}
+		c.errorf(path, "field %s not found on type %s%s", f.Name, t.Name, suggest(f.Name, names))
+		return
+	}
+	if def.Deprecated {
+		c.warnf(path, "field %s.%s is deprecated: %s", t.Name, def.Name, def.DeprecationReason)
+	}
+
+	for _, arg := range f.Arguments {
+		argDef := def.Arg(arg.Name)
+		if argDef == nil {
+			names := make([]string, len(def.Args))
+			for i, a := range def.Args {
+				names[i] = a.Name
+			}
+			c.errorf(path, "unknown argument %s of %s.%s%s", arg.Name, t.Name, def.Name, suggest(arg.Name, names))
+			continue
+		}
+		c.value(arg.Value, argDef.Type, path+"("+arg.Name+")")
+	}
+	for _, argDef := range def.Args {
+		if argDef.Type.NonNull && argDef.Default == nil && f.Argument(argDef.Name) == nil {
+			c.errorf(path, "required argument %s of type %s not given", argDef.Name, argDef.Type)
+		}
+	}
+
+	fieldType := c.schema.Types[def.Type.NamedType()]
+	if fieldType == nil {
+		c.errorf(path, "schema refers to unknown type %s", def.Type.NamedType())
+		return
+	}
+	isLeaf := fieldType.Kind == KindScalar || fieldType.Kind == KindEnum
+	switch {
+	case isLeaf && len(f.SelectionSet) > 0:
+		c.errorf(path, "type mismatch: %s is %s, which has no fields to select", f.Name, def.Type)
+	case !isLeaf && len(f.SelectionSet) == 0:
+		c.errorf(path, "type mismatch: %s is %s, which needs fields selected", f.Name, def.Type)
+	default:
+		c.selectionSet(f.SelectionSet, fieldType, path)
+	}
+}
+
+// value checks an input value against the expected type
+func (c *checker) value(v *Value, t *TypeRef, path string) {
+	switch v.Kind {
+	case ValueVariable:
+		varType, found := c.vars[v.Raw]
+		if !found {
+			c.errorf(path, "variable $%s not defined", v.Raw)
+		} else if !assignable(varType, t) {
+			c.errorf(path, "type mismatch: variable $%s of type %s given for %s", v.Raw, varType, t)
+		}
+		return
+	case ValueNull:
+		if t.NonNull {
+			c.errorf(path, "null given for %s", t)
+		}
+		return
+	}
+
+	if t.Elem != nil {
+		if v.Kind != ValueList {
+			// a single value is coerced into a list
+			c.value(v, t.Elem, path)
+			return
+		}
+		for i, item := range v.List {
+			c.value(item, t.Elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return
+	}
+
+	def := c.schema.Types[t.Name]
+	if def == nil {
+		return
+	}
+	switch def.Kind {
+	case KindEnum:
+		if v.Kind != ValueEnum {
+			c.errorf(path, "type mismatch: expects a value of enum %s", def.Name)
+			return
+		}
+		if ev := def.EnumValue(v.Raw); ev == nil {
+			c.errorf(path, "%s is not a value of enum %s", v.Raw, def.Name)
+		} else if ev.Deprecated {
+			c.warnf(path, "enum value %s.%s is deprecated: %s", def.Name, ev.Name, ev.DeprecationReason)
+		}
+	case KindInputObject:
+		if v.Kind != ValueObject {
+			c.errorf(path, "type mismatch: expects an object of type %s", def.Name)
+			return
+		}
+		for _, f := range v.Fields {
+			fieldDef := def.InputField(f.Name)
+			if fieldDef == nil {
+				names := make([]string, len(def.InputFields))
+				for i, field := range def.InputFields {
+					names[i] = field.Name
+				}
+				c.errorf(path, "unknown field %s of input %s%s", f.Name, def.Name, suggest(f.Name, names))
+				continue
+			}
+			c.value(f.Value, fieldDef.Type, path+"."+f.Name)
+		}
+		for _, fieldDef := range def.InputFields {
+			if fieldDef.Type.NonNull && fieldDef.Default == nil && fieldOf(v, fieldDef.Name) == nil {
+				c.errorf(path, "required field %s of input %s not given", fieldDef.Name, def.Name)
+			}
+		}
+	case KindScalar:
+		if !scalarAccepts(def.Name, v.Kind) {
+			c.errorf(path, "type mismatch: %s literal given for %s", v.Raw, def.Name)
+		}
+	}
+}
+
+func fieldOf(v *Value, name string) *Value {
+	for _, f := range v.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+func scalarAccepts(scalar string, kind ValueKind) bool {
+	switch scalar {
+	case "Int":
+		return kind == ValueInt
+	case "Float":
+		return kind == ValueInt || kind == ValueFloat
+	case "String":
+		return kind == ValueString
+	case "Boolean":
+		return kind == ValueBoolean
+	case "ID":
+		return kind == ValueInt || kind == ValueString
+	}
+	// custom scalars take anything
+	return kind != ValueList && kind != ValueObject
+}
+
+// assignable tells whether a variable of type from can be given where type to is expected
+func assignable(from, to *TypeRef) bool {
+	if to.NonNull && !from.NonNull {
+		return false
+	}
+	if (from.Elem == nil) != (to.Elem == nil) {
+		return false
+	}
+	if from.Elem != nil {
+		return assignable(from.Elem, to.Elem)
+	}
+	return from.Name == to.Name
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggest hints the name among candidates that's likely what got renamed to, empty if none is close enough
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, name) {
+			return fmt.Sprintf(", did you mean %s?", candidate)
+		}
+		if d := distance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", best)
+}
+
+// distance is the Levenshtein distance of a & b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,130 @@
+package gql
+
+import (
+	"strings"
+	"testing"
+)
+
+const checkSDL = `
+type Query {
+	me: User!
+	task(id: ID!): Task
+	tasks(input: QueryTaskInput, first: Int = 10): [Task!]!
+	legacy: String @deprecated(reason: "use me")
+}
+type Mutation {
+	taskStart(id: ID!, description: String): Task!
+}
+type User { id: ID!, name: String, runningTask: Task }
+type Task { id: ID!, name: String!, status: TaskStatus!, dueDate: Time }
+enum TaskStatus { NOT_STARTED, DOING, DONE, OLD @deprecated }
+input QueryTaskInput { status: [TaskStatus!], search: String, parentID: ID, page: PageInput }
+input PageInput { limit: Int!, offset: Int = 0 }
+scalar Time
+`
+
+func check(t *testing.T, query string) []string {
+	t.Helper()
+	schema, err := ParseSchema(checkSDL)
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	doc, err := Parse(query)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", query, err)
+	}
+	op, err := doc.Operation("")
+	if err != nil {
+		t.Fatalf("Operation: %v", err)
+	}
+
+	var res []string
+	for _, issue := range Check(schema, doc, op) {
+		res = append(res, issue.String())
+	}
+	return res
+}
+
+func TestCheckValid(t *testing.T) {
+	for _, query := range []string{
+		`{ me { id name runningTask { id __typename } } }`,
+		`{ t: task(id: 1) { id } u: task(id: "abc") { name } }`,
+		`query($s: [TaskStatus!], $limit: Int!) {
+			tasks(input: {status: $s, search: "x", page: {limit: $limit}}) { id status dueDate }
+		}`,
+		// a single value is coerced into a list, & non-null variables fit nullable arguments
+		`query($id: ID!) { tasks(input: {status: DOING, parentID: $id}, first: 5) { id } }`,
+		`{ tasks(input: null) { ...f ... on Task { name } } } fragment f on Task { id }`,
+		`mutation($d: String) { taskStart(id: 1, description: $d) { id } }`,
+	} {
+		if issues := check(t, query); len(issues) > 0 {
+			t.Errorf("Check(%s) = %q, want no issues", query, issues)
+		}
+	}
+}
+
+func TestCheckIssues(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{`{ me { nmae } }`, "error: me.nmae: field nmae not found on type User, did you mean name?"},
+		{`{ me { ID } }`, "error: me.ID: field ID not found on type User, did you mean id?"},
+		{`{ me { foo } }`, "error: me.foo: field foo not found on type User"},
+		{`{ task { id } }`, "error: task: required argument id of type ID! not given"},
+		{`{ task(id: 1, ids: 2) { id } }`, "error: task: unknown argument ids of Query.task, did you mean id?"},
+		{`{ task(id: null) { id } }`, "error: task(id): null given for ID!"},
+		{`{ task(id: 1.5) { id } }`, "error: task(id): type mismatch: 1.5 literal given for ID"},
+		{`{ me }`, "error: me: type mismatch: me is User!, which needs fields selected"},
+		{`{ me { id { x } } }`, "error: me.id: type mismatch: id is ID!, which has no fields to select"},
+		{`{ legacy }`, "warning: legacy: field Query.legacy is deprecated: use me"},
+		{`{ tasks(input: {status: [OLD]}) { id } }`, "warning: tasks(input).status[0]: enum value TaskStatus.OLD is deprecated: No longer supported"},
+		{`{ tasks(input: {status: [WAITING]}) { id } }`, "error: tasks(input).status[0]: WAITING is not a value of enum TaskStatus"},
+		{`{ tasks(input: {status: "DONE"}) { id } }`, "error: tasks(input).status: type mismatch: expects a value of enum TaskStatus"},
+		{`{ tasks(input: {serach: "x"}) { id } }`, "error: tasks(input): unknown field serach of input QueryTaskInput, did you mean search?"},
+		{`{ tasks(input: {page: {offset: 1}}) { id } }`, "error: tasks(input).page: required field limit of input PageInput not given"},
+		{`{ tasks(input: "x") { id } }`, "error: tasks(input): type mismatch: expects an object of type QueryTaskInput"},
+		{`{ task(id: $id) { id } }`, "error: task(id): variable $id not defined"},
+		{`query($id: ID) { task(id: $id) { id } }`, "error: task(id): type mismatch: variable $id of type ID given for ID!"},
+		{`query($s: TaskStatus) { tasks(input: {status: $s}) { id } }`, "error: tasks(input).status: type mismatch: variable $s of type TaskStatus given for [TaskStatus!]"},
+		{`query($u: User) { me { id } }`, "error: $u: User is not an input type"},
+		{`query($u: Unknown) { me { id } }`, "error: $u: unknown type Unknown"},
+		{`{ me { ...f } }`, "error: me: unknown fragment f"},
+		{`{ me { ... on Person { id } } }`, "error: me: unknown type Person"},
+		{`subscription { me { id } }`, "error: schema has no subscription type"},
+	} {
+		issues := check(t, tc.query)
+		if len(issues) != 1 || issues[0] != tc.want {
+			t.Errorf("Check(%s) = %q, want %q", tc.query, issues, tc.want)
+		}
+	}
+}
+
+func TestCheckReportsAll(t *testing.T) {
+	issues := check(t, `mutation { taskStart(description: 1) { id nmae } }`)
+	want := []string{
+		"error: taskStart(description): type mismatch: 1 literal given for String",
+		"error: taskStart: required argument id of type ID! not given",
+		"error: taskStart.nmae: field nmae not found on type Task, did you mean name?",
+	}
+	if strings.Join(issues, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Check = %q, want %q", issues, want)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"name", "status", "dueDate"}
+	for _, tc := range []struct {
+		name, want string
+	}{
+		{"Name", ", did you mean name?"},
+		{"stauts", ", did you mean status?"},
+		{"due_date", ", did you mean dueDate?"},
+		{"id", ""},
+		{"description", ""},
+	} {
+		if got := suggest(tc.name, candidates); got != tc.want {
+			t.Errorf("suggest(%s) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
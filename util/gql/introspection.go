@@ -0,0 +1,204 @@
+package gql
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// IntrospectionQuery asks for the whole schema, decoded by FromIntrospection
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types { ...FullType }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+          }
+        }
+      }
+    }
+  }
+}`
+
+type introspectedTypeRef struct {
+	Kind   TypeKind             `json:"kind"`
+	Name   string               `json:"name"`
+	OfType *introspectedTypeRef `json:"ofType"`
+}
+
+type introspectedInputValue struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Type         *introspectedTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectedType struct {
+	Kind        TypeKind `json:"kind"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Fields      []struct {
+		Name              string                    `json:"name"`
+		Description       string                    `json:"description"`
+		Args              []*introspectedInputValue `json:"args"`
+		Type              *introspectedTypeRef      `json:"type"`
+		IsDeprecated      bool                      `json:"isDeprecated"`
+		DeprecationReason string                    `json:"deprecationReason"`
+	} `json:"fields"`
+	InputFields []*introspectedInputValue `json:"inputFields"`
+	Interfaces  []*introspectedTypeRef    `json:"interfaces"`
+	EnumValues  []struct {
+		Name              string `json:"name"`
+		Description       string `json:"description"`
+		IsDeprecated      bool   `json:"isDeprecated"`
+		DeprecationReason string `json:"deprecationReason"`
+	} `json:"enumValues"`
+	PossibleTypes []*introspectedTypeRef `json:"possibleTypes"`
+}
+
+// FromIntrospection builds the schema from the result of IntrospectionQuery, either the data or the whole response
+func FromIntrospection(b []byte) (*Schema, error) {
+	var resp struct {
+		Data *struct {
+			Schema *introspectedSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *introspectedSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, err
+	}
+
+	is := resp.Schema
+	if resp.Data != nil {
+		is = resp.Data.Schema
+	}
+	if is == nil {
+		return nil, errors.New("no __schema found in the introspection result")
+	}
+
+	s := NewSchema()
+	if is.QueryType != nil {
+		s.QueryType = is.QueryType.Name
+	}
+	if is.MutationType != nil {
+		s.MutationType = is.MutationType.Name
+	}
+
+	for _, it := range is.Types {
+		t := &TypeDef{Kind: it.Kind, Name: it.Name, Description: it.Description}
+		for _, f := range it.Fields {
+			t.Fields = append(t.Fields, &FieldDef{
+				Name:              f.Name,
+				Description:       f.Description,
+				Args:              inputValues(f.Args),
+				Type:              f.Type.typeRef(),
+				Deprecated:        f.IsDeprecated,
+				DeprecationReason: f.DeprecationReason,
+			})
+		}
+		t.InputFields = inputValues(it.InputFields)
+		for _, v := range it.EnumValues {
+			t.EnumValues = append(t.EnumValues, &EnumValueDef{
+				Name:              v.Name,
+				Description:       v.Description,
+				Deprecated:        v.IsDeprecated,
+				DeprecationReason: v.DeprecationReason,
+			})
+		}
+		for _, ref := range it.Interfaces {
+			t.Interfaces = append(t.Interfaces, ref.Name)
+		}
+		for _, ref := range it.PossibleTypes {
+			t.PossibleTypes = append(t.PossibleTypes, ref.Name)
+		}
+		s.Types[t.Name] = t
+	}
+	return s, nil
+}
+
+type introspectedSchema struct {
+	QueryType    *struct{ Name string } `json:"queryType"`
+	MutationType *struct{ Name string } `json:"mutationType"`
+	Types        []*introspectedType    `json:"types"`
+}
+
+func inputValues(values []*introspectedInputValue) []*InputValueDef {
+	var res []*InputValueDef
+	for _, v := range values {
+		def := &InputValueDef{Name: v.Name, Description: v.Description, Type: v.Type.typeRef()}
+		if v.DefaultValue != nil {
+			// the default is only told apart from none; its value is printed GraphQL, kept raw
+			def.Default = &Value{Kind: ValueString, Raw: *v.DefaultValue}
+		}
+		res = append(res, def)
+	}
+	return res
+}
+
+func (r *introspectedTypeRef) typeRef() *TypeRef {
+	if r == nil {
+		return nil
+	}
+
+	switch r.Kind {
+	case "NON_NULL":
+		t := r.OfType.typeRef()
+		if t != nil {
+			t.NonNull = true
+		}
+		return t
+	case "LIST":
+		return &TypeRef{Elem: r.OfType.typeRef()}
+	default:
+		return &TypeRef{Name: r.Name}
+	}
+}
@@ -0,0 +1,632 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Document is a parsed executable GraphQL document: operations & fragments
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation returns the operation by name. Empty name is allowed when the document has a single operation
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, fmt.Errorf("expect exactly 1 operation without operation name, got %d", len(d.Operations))
+		}
+		return d.Operations[0], nil
+	}
+
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation: %s", name)
+}
+
+type Operation struct {
+	// Type is one of query, mutation & subscription
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+}
+
+// TypeRef is a reference to a type, like `[ID!]!`. Elem is set for lists, otherwise Name is
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// NamedType is the name of the type, without list & non-null wrappers
+func (t *TypeRef) NamedType() string {
+	if t.Elem != nil {
+		return t.Elem.NamedType()
+	}
+	return t.Name
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+// Selection is one of *Field, *FragmentSpread & *InlineFragment
+type Selection interface {
+	isSelection()
+}
+
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+// ResponseKey is the key of the field in the response
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Argument returns the argument by name, nil if not given
+func (f *Field) Argument(name string) *Value {
+	for _, arg := range f.Arguments {
+		if arg.Name == name {
+			return arg.Value
+		}
+	}
+	return nil
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+func (*Field) isSelection()          {}
+func (*FragmentSpread) isSelection() {}
+func (*InlineFragment) isSelection() {}
+
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+type ValueKind int
+
+const (
+	ValueVariable ValueKind = iota
+	ValueInt
+	ValueFloat
+	ValueString
+	ValueBoolean
+	ValueNull
+	ValueEnum
+	ValueList
+	ValueObject
+)
+
+// Value is an input value. Raw holds scalars, enums & variable names
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+}
+
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
+
+// Resolve converts the value into Go values, as decoded from JSON: float64, string, bool, nil, []any & map[string]any.
+// Variables are looked up from vars
+func (v *Value) Resolve(vars map[string]any) any {
+	if v == nil {
+		return nil
+	}
+
+	switch v.Kind {
+	case ValueVariable:
+		return vars[v.Raw]
+	case ValueInt, ValueFloat:
+		f, _ := strconv.ParseFloat(v.Raw, 64)
+		return f
+	case ValueString, ValueEnum:
+		return v.Raw
+	case ValueBoolean:
+		return v.Raw == "true"
+	case ValueList:
+		res := make([]any, len(v.List))
+		for i, item := range v.List {
+			res[i] = item.Resolve(vars)
+		}
+		return res
+	case ValueObject:
+		res := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			res[f.Name] = f.Value.Resolve(vars)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// Variables lists the names of variables used in the value
+func (v *Value) Variables() []string {
+	if v == nil {
+		return nil
+	}
+
+	switch v.Kind {
+	case ValueVariable:
+		return []string{v.Raw}
+	case ValueList:
+		var res []string
+		for _, item := range v.List {
+			res = append(res, item.Variables()...)
+		}
+		return res
+	case ValueObject:
+		var res []string
+		for _, f := range v.Fields {
+			res = append(res, f.Value.Variables()...)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// Parse parses an executable document, as sent by clients
+func Parse(src string) (*Document, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: sel})
+		case p.peekName("query", "mutation", "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peekName("fragment"):
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.Fragments[f.Name] = f
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("no operation found in the document")
+	}
+	return doc, nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lex: &lexer{src: src}}
+	return p, p.advance()
+}
+
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lex.next()
+	return err
+}
+
+func (p *parser) unexpected() error {
+	return p.lex.errorf(p.tok.pos, "unexpected %s", p.tok)
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) peekName(names ...string) bool {
+	if p.tok.kind != tokenName {
+		return false
+	}
+	for _, name := range names {
+		if p.tok.value == name {
+			return true
+		}
+	}
+	return false
+}
+
+// skip advances if the token is the given punctuator
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.peek(punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.lex.errorf(p.tok.pos, "expect %q, got %s", punct, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.lex.errorf(p.tok.pos, "expect name, got %s", p.tok)
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) keyword(name string) error {
+	if !p.peekName(name) {
+		return p.lex.errorf(p.tok.pos, "expect %q, got %s", name, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: p.tok.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.tok.kind == tokenName {
+		if op.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if op.Variables, err = p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	op.SelectionSet, err = p.selectionSet()
+	return op, err
+}
+
+func (p *parser) variableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var res []*VariableDefinition
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		def := &VariableDefinition{Name: name}
+		if def.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if def.Default, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		res = append(res, def)
+	}
+	return res, p.advance()
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	var t *TypeRef
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("]"); err != nil {
+			return nil, err
+		}
+		t = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t = &TypeRef{Name: name}
+	}
+
+	var err error
+	t.NonNull, err = p.skip("!")
+	return t, err
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.keyword("on"); err != nil {
+		return nil, err
+	}
+	f := &Fragment{Name: name}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if _, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	f.SelectionSet, err = p.selectionSet()
+	return f, err
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var res []Selection
+	for !p.peek("}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.unexpected()
+		}
+
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, sel)
+	}
+	return res, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection()
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Field{Name: name}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.Alias = name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if f.Arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// fragmentSelection parses what follows "...": a fragment spread or an inline fragment
+func (p *parser) fragmentSelection() (Selection, error) {
+	if p.tok.kind == tokenName && !p.peekName("on") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		spread := &FragmentSpread{Name: name}
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+
+	f := &InlineFragment{}
+	var err error
+	if p.peekName("on") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		if f.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	f.SelectionSet, err = p.selectionSet()
+	return f, err
+}
+
+func (p *parser) arguments(isConst bool) ([]*Argument, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var res []*Argument
+	for !p.peek(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.value(isConst)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &Argument{Name: name, Value: value})
+	}
+	return res, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	var res []*Directive
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		d := &Directive{Name: name}
+		if p.peek("(") {
+			if d.Arguments, err = p.arguments(false); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+func (p *parser) value(isConst bool) (*Value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		return &Value{Kind: ValueInt, Raw: tok.value}, p.advance()
+	case tokenFloat:
+		return &Value{Kind: ValueFloat, Raw: tok.value}, p.advance()
+	case tokenString:
+		return &Value{Kind: ValueString, Raw: tok.value}, p.advance()
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			return &Value{Kind: ValueBoolean, Raw: tok.value}, p.advance()
+		case "null":
+			return &Value{Kind: ValueNull}, p.advance()
+		default:
+			return &Value{Kind: ValueEnum, Raw: tok.value}, p.advance()
+		}
+	}
+
+	switch {
+	case p.peek("$") && !isConst:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return &Value{Kind: ValueVariable, Raw: name}, err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		v := &Value{Kind: ValueList}
+		for !p.peek("]") {
+			item, err := p.value(isConst)
+			if err != nil {
+				return nil, err
+			}
+			v.List = append(v.List, item)
+		}
+		return v, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		v := &Value{Kind: ValueObject}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect(":"); err != nil {
+				return nil, err
+			}
+			fv, err := p.value(isConst)
+			if err != nil {
+				return nil, err
+			}
+			v.Fields = append(v.Fields, &ObjectField{Name: name, Value: fv})
+		}
+		return v, p.advance()
+	}
+	return nil, p.unexpected()
+}
@@ -0,0 +1,244 @@
+package gql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# the tasks of the user
+		query Tasks($status: [TaskStatus!] = [DOING], $limit: Int!) @cached {
+			me { id }
+			open: tasks(input: {status: $status, limit: $limit}) @include(if: true) {
+				...taskFields
+				... on Task { dueDate }
+				... @skip(if: false) { name }
+			}
+		}
+
+		fragment taskFields on Task { id, name }
+
+		mutation { taskStart(id: 1) { id } }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(doc.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(doc.Operations))
+	}
+	op, err := doc.Operation("Tasks")
+	if err != nil {
+		t.Fatalf("Operation(Tasks): %v", err)
+	}
+	if op.Type != "query" || len(op.Variables) != 2 {
+		t.Fatalf("operation = %+v, want a query with 2 variables", op)
+	}
+	if v := op.Variables[0]; v.Name != "status" || v.Type.String() != "[TaskStatus!]" || v.Default == nil || v.Default.Kind != ValueList {
+		t.Errorf("variable = %+v, want $status: [TaskStatus!] = [DOING]", v)
+	}
+	if v := op.Variables[1]; v.Type.String() != "Int!" || v.Type.NamedType() != "Int" {
+		t.Errorf("variable type = %s, want Int!", v.Type)
+	}
+
+	open := op.SelectionSet[1].(*Field)
+	if open.ResponseKey() != "open" || open.Name != "tasks" || len(open.Directives) != 1 {
+		t.Errorf("field = %+v, want tasks aliased as open, with a directive", open)
+	}
+	if got := open.Argument("input").Variables(); !reflect.DeepEqual(got, []string{"status", "limit"}) {
+		t.Errorf("variables of the input = %v, want status & limit", got)
+	}
+	if spread, ok := open.SelectionSet[0].(*FragmentSpread); !ok || spread.Name != "taskFields" {
+		t.Errorf("selection = %#v, want the spread of taskFields", open.SelectionSet[0])
+	}
+	if inline, ok := open.SelectionSet[1].(*InlineFragment); !ok || inline.TypeCondition != "Task" {
+		t.Errorf("selection = %#v, want an inline fragment on Task", open.SelectionSet[1])
+	}
+	if inline, ok := open.SelectionSet[2].(*InlineFragment); !ok || inline.TypeCondition != "" || len(inline.Directives) != 1 {
+		t.Errorf("selection = %#v, want an inline fragment without type condition", open.SelectionSet[2])
+	}
+
+	if f := doc.Fragments["taskFields"]; f == nil || f.TypeCondition != "Task" || len(f.SelectionSet) != 2 {
+		t.Errorf("fragment = %+v, want taskFields on Task with 2 fields", f)
+	}
+	if _, err = doc.Operation(""); err == nil {
+		t.Error("Operation without name in a document of 2 = nil error, want one")
+	}
+	if _, err = doc.Operation("Unknown"); err == nil {
+		t.Error("Operation(Unknown) = nil error, want one")
+	}
+}
+
+func TestParseShorthandQuery(t *testing.T) {
+	doc, err := Parse("{ me { id } }")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	op, err := doc.Operation("")
+	if err != nil || op.Type != "query" || op.Name != "" {
+		t.Errorf("Operation = %+v, %v, want the anonymous query", op, err)
+	}
+}
+
+func TestValueResolve(t *testing.T) {
+	vars := map[string]any{"name": "from vars"}
+	for _, tc := range []struct {
+		literal string
+		want    any
+	}{
+		{`12`, 12.0},
+		{`-1.5e3`, -1500.0},
+		{`"a\"b\\c\né"`, "a\"b\\c\né"},
+		{`"日本"`, "日本"},
+		{`"caf\u00e9"`, "café"},
+		{"\"\"\"\n    first\n      indented\n    last\n  \"\"\"", "first\n  indented\nlast"},
+		{`"""say \""" twice \""""""`, `say """ twice """`},
+		{`true`, true},
+		{`null`, nil},
+		{`DOING`, "DOING"},
+		{`$name`, "from vars"},
+		{`$missing`, nil},
+		{`[1, "a", [true]]`, []any{1.0, "a", []any{true}}},
+		{`{a: 1, b: {c: $name}}`, map[string]any{"a": 1.0, "b": map[string]any{"c": "from vars"}}},
+	} {
+		doc, err := Parse("{ f(v: " + tc.literal + ") }")
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tc.literal, err)
+			continue
+		}
+		v := doc.Operations[0].SelectionSet[0].(*Field).Argument("v")
+		if got := v.Resolve(vars); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s resolved = %#v, want %#v", tc.literal, got, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{"", "no operation"},
+		{"fragment f on T { id }", "no operation"},
+		{"{ me { id }", "syntax error at 1:12"},
+		{"{\n  me(id: ) }", "syntax error at 2:10"},
+		{`{ f(s: "unterminated) }`, "unterminated string"},
+		{"{ f(s: \"line\nbreak\") }", "unterminated string"},
+		{`{ f(s: "\q") }`, `invalid escape \q`},
+		{`{ f(s: "\u12") }`, "invalid unicode escape"},
+		{`{ f(s: "\u12x4") }`, "invalid unicode escape"},
+		{`{ f(s: """open) }`, "unterminated block string"},
+		{"{ f(n: 1.) }", "invalid number"},
+		{"query($v: Int = $w) { f }", "syntax error"},
+		{"subscription { f } garbage", "syntax error"},
+	} {
+		_, err := Parse(tc.src)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) err = %v, want %q", tc.src, err, tc.want)
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema(`
+		schema { query: Root, mutation: Mutations }
+		directive @auth(role: String) repeatable on FIELD_DEFINITION | OBJECT
+
+		"""
+		The root
+		"""
+		type Root {
+			"the user"
+			me: User! @auth
+			old: String @deprecated(reason: "use me")
+			older: String @deprecated
+			tasks(first: Int = 10, status: [Status!]!): [Task!]!
+		}
+		type Mutations { noop: Boolean }
+		interface Node { id: ID! }
+		type User implements & Node & Named { id: ID!, name: String }
+		type Task implements Node { id: ID! }
+		union Result = | User | Task
+		enum Status { OPEN, DONE @deprecated }
+		input Filter { status: Status = OPEN, text: String! }
+		scalar Time
+		extend type Root { time: Time }
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+
+	root := s.RootType("query")
+	if root == nil || root.Name != "Root" || root.Description != "The root" {
+		t.Fatalf("query type = %+v, want Root with its description", root)
+	}
+	if s.RootType("mutation").Name != "Mutations" || s.RootType("subscription") != nil {
+		t.Errorf("root types = %s & %v, want Mutations & none", s.MutationType, s.RootType("subscription"))
+	}
+	if f := root.Field("me"); f == nil || f.Description != "the user" || f.Type.String() != "User!" {
+		t.Errorf("field me = %+v, want User! with its description", f)
+	}
+	if f := root.Field("old"); !f.Deprecated || f.DeprecationReason != "use me" {
+		t.Errorf("field old = %+v, want deprecated to use me", f)
+	}
+	if f := root.Field("older"); !f.Deprecated || f.DeprecationReason != defaultDeprecationReason {
+		t.Errorf("field older = %+v, want deprecated with the default reason", f)
+	}
+	tasks := root.Field("tasks")
+	if a := tasks.Arg("first"); a == nil || a.Default == nil || a.Default.Raw != "10" {
+		t.Errorf("arg first = %+v, want defaulting to 10", a)
+	}
+	if a := tasks.Arg("status"); a == nil || a.Type.String() != "[Status!]!" {
+		t.Errorf("arg status = %+v, want [Status!]!", a)
+	}
+	if root.Field("time") == nil {
+		t.Error("field time of the extension not found")
+	}
+
+	if u := s.Types["User"]; u.Kind != KindObject || !reflect.DeepEqual(u.Interfaces, []string{"Node", "Named"}) {
+		t.Errorf("User = %+v, want an object implementing Node & Named", u)
+	}
+	if n := s.Types["Node"]; n.Kind != KindInterface {
+		t.Errorf("Node kind = %s, want INTERFACE", n.Kind)
+	}
+	if r := s.Types["Result"]; r.Kind != KindUnion || !reflect.DeepEqual(r.PossibleTypes, []string{"User", "Task"}) {
+		t.Errorf("Result = %+v, want the union of User & Task", r)
+	}
+	if e := s.Types["Status"]; e.Kind != KindEnum || e.EnumValue("OPEN") == nil || !e.EnumValue("DONE").Deprecated {
+		t.Errorf("Status = %+v, want an enum of OPEN & deprecated DONE", e)
+	}
+	if in := s.Types["Filter"]; in.Kind != KindInputObject || in.InputField("status").Default.Raw != "OPEN" {
+		t.Errorf("Filter = %+v, want an input with status defaulting to OPEN", in)
+	}
+	if st := s.Types["Time"]; st == nil || st.Kind != KindScalar {
+		t.Errorf("Time = %+v, want a scalar", st)
+	}
+	if s.Types["String"] == nil {
+		t.Error("built-in scalar String not found")
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{"type A { a: Int } type A { b: Int }", "defined more than once"},
+		{"extend type A { a: Int }", "cannot extend undefined"},
+		{"enum E { A } extend type E { a: Int }", "cannot extend undefined"},
+		{"type A { a Int }", "syntax error"},
+		{"query { a }", "syntax error"},
+	} {
+		_, err := ParseSchema(tc.src)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseSchema(%q) err = %v, want %q", tc.src, err, tc.want)
+		}
+	}
+
+	// built-in scalars may be redefined, like in schemas dumped from servers
+	if _, err := ParseSchema("scalar String"); err != nil {
+		t.Errorf("ParseSchema redefining String: %v", err)
+	}
+}
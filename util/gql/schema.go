@@ -0,0 +1,482 @@
+package gql
+
+import "fmt"
+
+type TypeKind string
+
+const (
+	KindScalar      TypeKind = "SCALAR"
+	KindObject      TypeKind = "OBJECT"
+	KindInterface   TypeKind = "INTERFACE"
+	KindUnion       TypeKind = "UNION"
+	KindEnum        TypeKind = "ENUM"
+	KindInputObject TypeKind = "INPUT_OBJECT"
+)
+
+// Schema is a parsed schema definition (SDL)
+type Schema struct {
+	Types        map[string]*TypeDef
+	QueryType    string
+	MutationType string
+}
+
+type TypeDef struct {
+	Kind        TypeKind
+	Name        string
+	Description string
+	// Fields of objects & interfaces
+	Fields []*FieldDef
+	// InputFields of input objects
+	InputFields   []*InputValueDef
+	EnumValues    []*EnumValueDef
+	Interfaces    []string
+	PossibleTypes []string
+}
+
+// Field returns the field by name, nil if not found
+func (t *TypeDef) Field(name string) *FieldDef {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InputField returns the input field by name, nil if not found
+func (t *TypeDef) InputField(name string) *InputValueDef {
+	for _, f := range t.InputFields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// EnumValue returns the enum value by name, nil if not found
+func (t *TypeDef) EnumValue(name string) *EnumValueDef {
+	for _, v := range t.EnumValues {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+type FieldDef struct {
+	Name              string
+	Description       string
+	Args              []*InputValueDef
+	Type              *TypeRef
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// Arg returns the argument by name, nil if not found
+func (f *FieldDef) Arg(name string) *InputValueDef {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+type InputValueDef struct {
+	Name        string
+	Description string
+	Type        *TypeRef
+	Default     *Value
+}
+
+type EnumValueDef struct {
+	Name              string
+	Description       string
+	Deprecated        bool
+	DeprecationReason string
+}
+
+const defaultDeprecationReason = "No longer supported"
+
+var builtinScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+// NewSchema creates an empty schema with the built-in scalars
+func NewSchema() *Schema {
+	s := &Schema{Types: map[string]*TypeDef{}, QueryType: "Query", MutationType: "Mutation"}
+	for _, name := range builtinScalars {
+		s.Types[name] = &TypeDef{Kind: KindScalar, Name: name}
+	}
+	return s
+}
+
+// RootType returns the root type for the operation type: query or mutation
+func (s *Schema) RootType(operation string) *TypeDef {
+	switch operation {
+	case "query":
+		return s.Types[s.QueryType]
+	case "mutation":
+		return s.Types[s.MutationType]
+	}
+	return nil
+}
+
+// ParseSchema parses a schema in SDL. Directive definitions are accepted but not kept
+func ParseSchema(src string) (*Schema, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewSchema()
+	for p.tok.kind != tokenEOF {
+		desc, err := p.description()
+		if err != nil {
+			return nil, err
+		}
+
+		extend := p.peekName("extend")
+		if extend {
+			if err = p.advance(); err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case p.peekName("schema"):
+			err = p.schemaDefinition(s)
+		case p.peekName("directive"):
+			err = p.directiveDefinition()
+		case p.peekName("scalar", "type", "interface", "union", "enum", "input"):
+			var t *TypeDef
+			if t, err = p.typeDefinition(); err != nil {
+				return nil, err
+			}
+			t.Description = desc
+			err = s.addType(t, extend)
+		default:
+			err = p.unexpected()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) addType(t *TypeDef, extend bool) error {
+	existing, found := s.Types[t.Name]
+	if !extend {
+		if found && existing.Kind != KindScalar {
+			return fmt.Errorf("type %s defined more than once", t.Name)
+		}
+		s.Types[t.Name] = t
+		return nil
+	}
+
+	if !found || existing.Kind != t.Kind {
+		return fmt.Errorf("cannot extend undefined %s %s", t.Kind, t.Name)
+	}
+	existing.Fields = append(existing.Fields, t.Fields...)
+	existing.InputFields = append(existing.InputFields, t.InputFields...)
+	existing.EnumValues = append(existing.EnumValues, t.EnumValues...)
+	existing.Interfaces = append(existing.Interfaces, t.Interfaces...)
+	existing.PossibleTypes = append(existing.PossibleTypes, t.PossibleTypes...)
+	return nil
+}
+
+func (p *parser) description() (string, error) {
+	if p.tok.kind != tokenString {
+		return "", nil
+	}
+	desc := p.tok.value
+	return desc, p.advance()
+}
+
+func (p *parser) schemaDefinition(s *Schema) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for !p.peek("}") {
+		op, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case "query":
+			s.QueryType = name
+		case "mutation":
+			s.MutationType = name
+		}
+	}
+	return p.advance()
+}
+
+func (p *parser) directiveDefinition() error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peek("(") {
+		if _, err := p.inputValueDefinitions("(", ")"); err != nil {
+			return err
+		}
+	}
+	if p.peekName("repeatable") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if err := p.keyword("on"); err != nil {
+		return err
+	}
+
+	if _, err := p.skip("|"); err != nil {
+		return err
+	}
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if ok, err := p.skip("|"); err != nil || !ok {
+			return err
+		}
+	}
+}
+
+func (p *parser) typeDefinition() (*TypeDef, error) {
+	keyword := p.tok.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	t := &TypeDef{Name: name}
+
+	switch keyword {
+	case "scalar":
+		t.Kind = KindScalar
+		_, err = p.directives()
+	case "type", "interface":
+		t.Kind = KindObject
+		if keyword == "interface" {
+			t.Kind = KindInterface
+		}
+		if t.Interfaces, err = p.implements(); err != nil {
+			return nil, err
+		}
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if p.peek("{") {
+			t.Fields, err = p.fieldDefinitions()
+		}
+	case "union":
+		t.Kind = KindUnion
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil || !ok {
+			return t, err
+		}
+		if _, err = p.skip("|"); err != nil {
+			return nil, err
+		}
+		for {
+			member, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			t.PossibleTypes = append(t.PossibleTypes, member)
+			if ok, err := p.skip("|"); err != nil {
+				return nil, err
+			} else if !ok {
+				break
+			}
+		}
+	case "enum":
+		t.Kind = KindEnum
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if p.peek("{") {
+			t.EnumValues, err = p.enumValues()
+		}
+	case "input":
+		t.Kind = KindInputObject
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if p.peek("{") {
+			t.InputFields, err = p.inputValueDefinitions("{", "}")
+		}
+	}
+	return t, err
+}
+
+func (p *parser) implements() ([]string, error) {
+	if !p.peekName("implements") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var res []string
+	if _, err := p.skip("&"); err != nil {
+		return nil, err
+	}
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, name)
+		if ok, err := p.skip("&"); err != nil {
+			return nil, err
+		} else if !ok {
+			return res, nil
+		}
+	}
+}
+
+func (p *parser) fieldDefinitions() ([]*FieldDef, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var res []*FieldDef
+	for !p.peek("}") {
+		desc, err := p.description()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		f := &FieldDef{Name: name, Description: desc}
+		if p.peek("(") {
+			if f.Args, err = p.inputValueDefinitions("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if f.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+
+		directives, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		f.Deprecated, f.DeprecationReason = deprecation(directives)
+		res = append(res, f)
+	}
+	return res, p.advance()
+}
+
+func (p *parser) inputValueDefinitions(open, close string) ([]*InputValueDef, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+
+	var res []*InputValueDef
+	for !p.peek(close) {
+		desc, err := p.description()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		v := &InputValueDef{Name: name, Description: desc}
+		if v.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if v.Default, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, p.advance()
+}
+
+func (p *parser) enumValues() ([]*EnumValueDef, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var res []*EnumValueDef
+	for !p.peek("}") {
+		desc, err := p.description()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		v := &EnumValueDef{Name: name, Description: desc}
+		directives, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		v.Deprecated, v.DeprecationReason = deprecation(directives)
+		res = append(res, v)
+	}
+	return res, p.advance()
+}
+
+func deprecation(directives []*Directive) (bool, string) {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		for _, arg := range d.Arguments {
+			if arg.Name == "reason" && arg.Value.Kind == ValueString {
+				return true, arg.Value.Raw
+			}
+		}
+		return true, defaultDeprecationReason
+	}
+	return false, ""
+}
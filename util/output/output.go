@@ -0,0 +1,109 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+)
+
+var Formats = []Format{FormatText, FormatJSON, FormatYAML, FormatCSV}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format: %s, expect one of %v", s, Formats)
+}
+
+// Tabular is implemented by documents which can be written as CSV
+type Tabular interface {
+	Header() []string
+	Rows() [][]string
+}
+
+type Printer struct {
+	Format Format
+	// Template overrides Format when set
+	Template *template.Template
+	W        io.Writer
+}
+
+// IsStructured tells whether the printer writes machine-readable documents
+func (p *Printer) IsStructured() bool {
+	return p.Format != FormatText || p.Template != nil
+}
+
+// Print writes v as a single document. For FormatText, the text func is called instead
+func (p *Printer) Print(v any, text func()) error {
+	if p.Template != nil {
+		return p.printTemplate(v)
+	}
+
+	switch p.Format {
+	case FormatJSON:
+		enc := json.NewEncoder(p.W)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case FormatYAML:
+		return p.printYAML(v)
+	case FormatCSV:
+		t, ok := v.(Tabular)
+		if !ok {
+			return fmt.Errorf("csv output isn't supported for %T", v)
+		}
+		w := csv.NewWriter(p.W)
+		w.Write(t.Header())
+		w.WriteAll(t.Rows())
+		return w.Error()
+	default:
+		if text != nil {
+			text()
+		}
+		return nil
+	}
+}
+
+// printYAML goes through JSON, so the json tags (and marshalers) define the document for both
+func (p *Printer) printYAML(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(b, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+
+	enc := yaml.NewEncoder(p.W)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// resetStyle drops the JSON flow & quoting style, so the output reads as block YAML
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stream writes a list an item at a time, as the items come, so they needn't all be loaded first.
+// The output is the same as Print with the whole list
+type Stream struct {
+	p      *Printer
+	header []string
+	text   func(v any)
+
+	n   int
+	csv *csv.Writer
+}
+
+// Stream starts writing a list. header is the CSV header; for FormatText, the text func is called per item
+func (p *Printer) Stream(header []string, text func(v any)) *Stream {
+	return &Stream{p: p, header: header, text: text}
+}
+
+// Item writes an item of the list. For CSV, it must be Tabular
+func (s *Stream) Item(v any) error {
+	defer func() { s.n++ }()
+
+	if s.p.Template != nil {
+		return s.p.executeTemplate(v)
+	}
+
+	switch s.p.Format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(v, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if s.n == 0 {
+			sep = "[\n  "
+		}
+		_, err = fmt.Fprintf(s.p.W, "%s%s", sep, b)
+		return err
+	case FormatYAML:
+		return s.yamlItem(v)
+	case FormatCSV:
+		t, ok := v.(Tabular)
+		if !ok {
+			return fmt.Errorf("csv output isn't supported for %T", v)
+		}
+		if s.n == 0 {
+			s.csv = csv.NewWriter(s.p.W)
+			s.csv.Write(s.header)
+		}
+		s.csv.WriteAll(t.Rows())
+		return s.csv.Error()
+	default:
+		if s.text != nil {
+			s.text(v)
+		}
+		return nil
+	}
+}
+
+// yamlItem writes the item as a single-item sequence; they add up to the whole list
+func (s *Stream) yamlItem(v any) error {
+	b, err := json.Marshal([]any{v})
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(b, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+
+	enc := yaml.NewEncoder(s.p.W)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Close ends the list. Lists without items are written as empty
+func (s *Stream) Close() error {
+	if s.p.Template != nil {
+		return nil
+	}
+
+	var err error
+	switch s.p.Format {
+	case FormatJSON:
+		if s.n == 0 {
+			_, err = io.WriteString(s.p.W, "[]\n")
+		} else {
+			_, err = io.WriteString(s.p.W, "\n]\n")
+		}
+	case FormatYAML:
+		if s.n == 0 {
+			_, err = io.WriteString(s.p.W, "[]\n")
+		}
+	case FormatCSV:
+		if s.n == 0 {
+			s.csv = csv.NewWriter(s.p.W)
+			s.csv.Write(s.header)
+		}
+		s.csv.Flush()
+		err = s.csv.Error()
+	}
+	return err
+}
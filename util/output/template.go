@@ -0,0 +1,165 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/todopeer/cli/util/dt"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// CalendarDayer is implemented by types holding a calendar day rather than a point of time, like due dates. Time
+// helpers show the day as is, not shifted into the time zone
+type CalendarDayer interface {
+	// CalendarDay is midnight UTC of the day
+	CalendarDay() time.Time
+}
+
+// Durationer is implemented by types holding a duration, for the duration helper of templates
+type Durationer interface {
+	Duration() time.Duration
+}
+
+// templateFuncs are the helper funcs of templates, showing times in the time zone
+func templateFuncs(loc *time.Location) template.FuncMap {
+	return template.FuncMap{
+		"duration": func(v any) (string, error) {
+			d, ok, err := toDuration(v)
+			if !ok {
+				return "", err
+			}
+			return dt.FormatDuration(d, false), nil
+		},
+		"date":     timeFormatter(time.DateOnly, loc),
+		"time":     timeFormatter(time.TimeOnly, loc),
+		"datetime": timeFormatter(time.DateTime, loc),
+		"relative": func(v any) string {
+			t, ok := toTime(v)
+			if !ok {
+				return ""
+			}
+			return dt.Relative(t, time.Now())
+		},
+		"day": func(v any) string {
+			t, ok := toTime(v)
+			if !ok {
+				return ""
+			}
+			return dt.Day(t, time.Now(), loc)
+		},
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// ParseTemplate parses the --format template, with helper funcs:
+// duration, date, time, datetime (in the time zone, but for calendar days like due dates), relative (like "in 3d"), day (like "yesterday 14:05"),
+// json, upper & lower
+func ParseTemplate(text string, loc *time.Location) (*template.Template, error) {
+	return template.New("format").Funcs(templateFuncs(loc)).Parse(text)
+}
+
+// printTemplate renders v through the template. Lists are rendered one item per line
+func (p *Printer) printTemplate(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return p.executeTemplate(v)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := p.executeTemplate(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Printer) executeTemplate(v any) error {
+	var sb strings.Builder
+	if err := p.Template.Execute(&sb, v); err != nil {
+		return err
+	}
+
+	s := sb.String()
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, err := fmt.Fprint(p.W, s)
+	return err
+}
+
+func timeFormatter(layout string, loc *time.Location) func(v any) string {
+	return func(v any) string {
+		if day, ok := calendarDay(v); ok {
+			return day.Format(layout)
+		}
+		t, ok := toTime(v)
+		if !ok {
+			return ""
+		}
+		return t.In(loc).Format(layout)
+	}
+}
+
+// calendarDay gives the day of CalendarDayer values, false for others & nil pointers
+func calendarDay(v any) (time.Time, bool) {
+	day, ok := v.(CalendarDayer)
+	if !ok {
+		return time.Time{}, false
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return time.Time{}, false
+	}
+	return day.CalendarDay(), true
+}
+
+// toTime accepts time.Time and any type defined on it (like api.Time), or pointers of them
+func toTime(v any) (time.Time, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return time.Time{}, false
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || !rv.Type().ConvertibleTo(timeType) {
+		return time.Time{}, false
+	}
+	return rv.Convert(timeType).Interface().(time.Time), true
+}
+
+// toDuration accepts time.Duration and Durationer, or pointers of them. Nil is no duration, other types an error:
+// integers like IDs aren't durations
+func toDuration(v any) (time.Duration, bool, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return 0, false, nil
+		}
+		if d, ok := rv.Interface().(Durationer); ok {
+			return d.Duration(), true, nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return 0, false, nil
+	}
+
+	switch d := rv.Interface().(type) {
+	case time.Duration:
+		return d, true, nil
+	case Durationer:
+		return d.Duration(), true, nil
+	}
+	return 0, false, fmt.Errorf("duration expects a duration, got %s", rv.Type())
+}
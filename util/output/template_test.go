@@ -0,0 +1,49 @@
+package output_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/todopeer/cli/api"
+	"github.com/todopeer/cli/util/output"
+)
+
+func TestTemplateTimes(t *testing.T) {
+	la := time.FixedZone("UTC-7", -7*60*60)
+	var task struct {
+		DueDate   *api.Date
+		CreatedAt api.Time
+		Missing   *api.Date
+	}
+	if err := json.Unmarshal([]byte(`{"DueDate":"2024-05-10T00:00:00Z","CreatedAt":"2024-05-10T03:00:00Z"}`), &task); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		format, want string
+	}{
+		// due dates are days, the same in any time zone
+		{"{{.DueDate}}", "2024-05-10"},
+		{"{{date .DueDate}}", "2024-05-10"},
+		{"{{datetime .DueDate}}", "2024-05-10 00:00:00"},
+		{"{{date .Missing}}|{{.Missing}}", "|"},
+		// points of time are shown in the time zone
+		{"{{datetime .CreatedAt}}", "2024-05-09 20:00:00"},
+		{"{{time .CreatedAt}}", "20:00:00"},
+		{"{{.CreatedAt}}", "2024-05-10 03:00:00"},
+	} {
+		tmpl, err := output.ParseTemplate(tc.format, la)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%s): %v", tc.format, err)
+		}
+		var sb strings.Builder
+		if err = tmpl.Execute(&sb, &task); err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		if sb.String() != tc.want {
+			t.Errorf("%s = %q, want %q", tc.format, sb.String(), tc.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package picker lets the user pick an item of a list on the terminal, narrowing the list down by typing
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ErrCanceled is returned when the user quits the picker without picking
+var ErrCanceled = errors.New("canceled")
+
+type Item struct {
+	// Label is the line shown for the item. It should fit the terminal width, lines wrapping break the layout
+	Label string
+	// Key is the text the filter typed matches, default to Label
+	Key string
+}
+
+// Pick shows the items below the prompt on out, and reads the keys from in, which must be a terminal.
+// Typing narrows the list down to the items with all the words typed; up & down (or ctrl-p & ctrl-n) move,
+// enter picks, esc & ctrl-c cancel. height is the number of items shown at once.
+// It gives the index of the item picked, or ErrCanceled
+func Pick(in *os.File, out io.Writer, prompt string, items []Item, height int) (int, error) {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return 0, err
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	p := &picker{out: out, prompt: prompt, items: items, height: height}
+	p.refilter()
+	defer p.clear()
+
+	buf := make([]byte, 64)
+	for {
+		p.draw()
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, io.EOF
+		}
+		switch key := buf[:n]; {
+		case key[0] == 3 || string(key) == "\x1b":
+			// ctrl-c, or esc alone
+			return 0, ErrCanceled
+		case key[0] == '\r' || key[0] == '\n':
+			if len(p.matched) == 0 {
+				continue
+			}
+			return p.matched[p.selected], nil
+		case string(key) == "\x1b[A" || string(key) == "\x1bOA" || key[0] == 16:
+			p.move(-1)
+		case string(key) == "\x1b[B" || string(key) == "\x1bOB" || key[0] == 14:
+			p.move(1)
+		case key[0] == 127 || key[0] == 8:
+			if len(p.filter) > 0 {
+				p.filter = p.filter[:len(p.filter)-1]
+				p.refilter()
+			}
+		case key[0] == 21:
+			// ctrl-u
+			p.filter = nil
+			p.refilter()
+		case key[0] != 0x1b:
+			for len(key) > 0 {
+				r, size := utf8.DecodeRune(key)
+				if unicode.IsPrint(r) {
+					p.filter = append(p.filter, r)
+				}
+				key = key[size:]
+			}
+			p.refilter()
+		}
+	}
+}
+
+type picker struct {
+	out    io.Writer
+	prompt string
+	items  []Item
+	height int
+
+	filter []rune
+	// matched are the indexes of the items matching the filter
+	matched  []int
+	selected int
+	// top is the first of matched shown
+	top int
+	// drawn is the number of lines drawn below the prompt
+	drawn int
+}
+
+func (p *picker) refilter() {
+	words := strings.Fields(strings.ToLower(string(p.filter)))
+	p.matched = p.matched[:0]
+next:
+	for i, item := range p.items {
+		key := item.Key
+		if key == "" {
+			key = item.Label
+		}
+		key = strings.ToLower(key)
+		for _, w := range words {
+			if !strings.Contains(key, w) {
+				continue next
+			}
+		}
+		p.matched = append(p.matched, i)
+	}
+	p.selected, p.top = 0, 0
+}
+
+func (p *picker) move(delta int) {
+	if len(p.matched) == 0 {
+		return
+	}
+	p.selected = (p.selected + delta + len(p.matched)) % len(p.matched)
+	if p.selected < p.top {
+		p.top = p.selected
+	}
+	if p.selected >= p.top+p.height {
+		p.top = p.selected - p.height + 1
+	}
+}
+
+// draw redraws the prompt & the list, leaving the cursor after the filter typed.
+// In raw mode, lines end with \r\n
+func (p *picker) draw() {
+	var b strings.Builder
+	b.WriteString("\r\x1b[J")
+	line := fmt.Sprintf("%s (%d/%d) > %s", p.prompt, len(p.matched), len(p.items), string(p.filter))
+	b.WriteString(line)
+
+	p.drawn = 0
+	for i := p.top; i < len(p.matched) && i < p.top+p.height; i++ {
+		label := p.items[p.matched[i]].Label
+		if i == p.selected {
+			// reversed, to stand out
+			fmt.Fprintf(&b, "\r\n\x1b[7m> %s\x1b[0m", label)
+		} else {
+			fmt.Fprintf(&b, "\r\n  %s", label)
+		}
+		p.drawn++
+	}
+	if len(p.matched) == 0 {
+		b.WriteString("\r\n  (no match)")
+		p.drawn++
+	}
+
+	// back to the end of the prompt line
+	fmt.Fprintf(&b, "\x1b[%dA\r", p.drawn)
+	if width := len([]rune(line)); width > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", width)
+	}
+	io.WriteString(p.out, b.String())
+}
+
+// clear removes the picker from the screen
+func (p *picker) clear() {
+	io.WriteString(p.out, "\r\x1b[J")
+}
@@ -0,0 +1,278 @@
+// Package rrule implements the subset of iCalendar recurrence rules (RFC 5545 RRULE) fit for due dates:
+// occurrences are days, with no time of the day
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/todopeer/cli/util/dt"
+)
+
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+	Yearly  Freq = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Rule is a schedule like FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH. The fields missing are taken from the start day of
+// the schedule: weekly rules recur on its weekday, monthly ones on its day of month, & yearly ones on its date
+type Rule struct {
+	Freq Freq
+	// Interval is every how many days, weeks, months or years, counted from the start. Default to 1
+	Interval int
+	// ByDay keeps the days of these weekdays. For daily & weekly rules
+	ByDay []time.Weekday
+	// ByMonthDay keeps these days of the month, negative ones counting from the end: -1 is the last day.
+	// For monthly rules
+	ByMonthDay []int
+	// Until is the last day the rule may recur on, if not zero. At midnight UTC, for its calendar date only
+	Until time.Time
+}
+
+// Parse parses rules like FREQ=WEEKLY;BYDAY=FR, with an optional RRULE: prefix. Parts are FREQ (DAILY, WEEKLY,
+// MONTHLY or YEARLY), INTERVAL, BYDAY, BYMONTHDAY & UNTIL (like 20231231)
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	r := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q, expect like FREQ=WEEKLY", part)
+		}
+
+		switch name {
+		case "FREQ":
+			r.Freq = Freq(value)
+			if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly && r.Freq != Yearly {
+				return nil, fmt.Errorf("unknown FREQ %s, expect one of DAILY, WEEKLY, MONTHLY & YEARLY", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive number: %s", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, found := weekdays[day]
+				if !found {
+					return nil, fmt.Errorf("unknown BYDAY %s, expect weekdays like MO,FR", day)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("BYMONTHDAY must be days of month, 1 to 31 or -31 to -1: %s", day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := time.Parse("20060102", strings.SplitN(value, "T", 2)[0])
+			if err != nil {
+				return nil, fmt.Errorf("UNTIL must be a date like 20231231: %s", value)
+			}
+			r.Until = until
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s, expect FREQ, INTERVAL, BYDAY, BYMONTHDAY or UNTIL", name)
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return nil, errors.New("FREQ is required, like FREQ=WEEKLY")
+	case len(r.ByDay) > 0 && r.Freq != Daily && r.Freq != Weekly:
+		return nil, errors.New("BYDAY is for DAILY & WEEKLY rules")
+	case len(r.ByMonthDay) > 0 && r.Freq != Monthly:
+		return nil, errors.New("BYMONTHDAY is for MONTHLY rules")
+	}
+	return r, nil
+}
+
+// String formats the rule as parsed by Parse
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = strings.ToUpper(d.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between gives the days the rule recurs on, for the schedule starting on start: after after, up to until included.
+// Days are at midnight, in the time zone of start
+func (r *Rule) Between(start, after, until time.Time) []time.Time {
+	loc := start.Location()
+	start, after, until = dayOf(start, loc), dayOf(after, loc), dayOf(until, loc)
+	if !r.Until.IsZero() {
+		if last := time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day(), 0, 0, 0, 0, loc); last.Before(until) {
+			until = last
+		}
+	}
+
+	from := after.AddDate(0, 0, 1)
+	if from.Before(start) {
+		from = start
+	}
+
+	var res []time.Time
+	for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+		if r.matches(start, day) {
+			res = append(res, day)
+		}
+	}
+	return res
+}
+
+// Next gives the first day the rule recurs on after after, within a few years, for the schedule starting on start.
+// It goes through the periods the rule may recur in only, every Interval days, weeks, months or years from the start
+func (r *Rule) Next(start, after time.Time) (time.Time, bool) {
+	loc := start.Location()
+	start, after = dayOf(start, loc), dayOf(after, loc)
+	// yearly rules on Feb 29 recur every 4 years, & intervals multiply that
+	limit := after.AddDate(4*r.Interval+1, 0, 0)
+	if !r.Until.IsZero() {
+		if last := time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day(), 0, 0, 0, 0, loc); last.Before(limit) {
+			limit = last
+		}
+	}
+
+	from := after.AddDate(0, 0, 1)
+	if from.Before(start) {
+		from = start
+	}
+	n := r.periodsBetween(start, from)
+	for n -= n % r.Interval; ; n += r.Interval {
+		first, last := r.period(start, n)
+		if first.After(limit) {
+			return time.Time{}, false
+		}
+		if first.Before(from) {
+			first = from
+		}
+		if days := r.Between(start, first.AddDate(0, 0, -1), last); len(days) > 0 {
+			return days[0], true
+		}
+	}
+}
+
+// periodsBetween counts the days, weeks, months or years from the one of start to the one of day
+func (r *Rule) periodsBetween(start, day time.Time) int {
+	switch r.Freq {
+	case Weekly:
+		return dt.DaysBetween(mondayOf(start), mondayOf(day)) / 7
+	case Monthly:
+		return (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+	case Yearly:
+		return day.Year() - start.Year()
+	}
+	return dt.DaysBetween(start, day)
+}
+
+// period gives the first & last days of the nth day, week, month or year since the one of start
+func (r *Rule) period(start time.Time, n int) (first, last time.Time) {
+	loc := start.Location()
+	switch r.Freq {
+	case Weekly:
+		first = mondayOf(start).AddDate(0, 0, 7*n)
+		return first, first.AddDate(0, 0, 6)
+	case Monthly:
+		first = time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, loc)
+		return first, first.AddDate(0, 1, -1)
+	case Yearly:
+		first = time.Date(start.Year()+n, time.January, 1, 0, 0, 0, 0, loc)
+		return first, first.AddDate(1, 0, -1)
+	}
+	first = start.AddDate(0, 0, n)
+	return first, first
+}
+
+func (r *Rule) matches(start, day time.Time) bool {
+	switch r.Freq {
+	case Daily:
+		return dt.DaysBetween(start, day)%r.Interval == 0 && r.onWeekday(day, time.Weekday(-1))
+	case Weekly:
+		weeks := dt.DaysBetween(mondayOf(start), mondayOf(day)) / 7
+		return weeks%r.Interval == 0 && r.onWeekday(day, start.Weekday())
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+		return months%r.Interval == 0 && r.onMonthDay(day, start.Day())
+	case Yearly:
+		return (day.Year()-start.Year())%r.Interval == 0 && day.Month() == start.Month() && day.Day() == start.Day()
+	}
+	return false
+}
+
+// onWeekday tells whether the day is on one of ByDay, or on the default weekday if there's none. Negative default
+// matches any weekday
+func (r *Rule) onWeekday(day time.Time, defaultWeekday time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return defaultWeekday < 0 || day.Weekday() == defaultWeekday
+	}
+	for _, weekday := range r.ByDay {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// onMonthDay tells whether the day is one of ByMonthDay, or the default day of month if there's none.
+// Months too short for the day skip it
+func (r *Rule) onMonthDay(day time.Time, defaultDay int) bool {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{defaultDay}
+	}
+
+	// day 0 of the next month is the last of this one
+	length := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range days {
+		if d < 0 {
+			d += length + 1
+		}
+		if day.Day() == d {
+			return true
+		}
+	}
+	return false
+}
+
+// dayOf is the start of the day of t, in the time zone
+func dayOf(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func mondayOf(day time.Time) time.Time {
+	return day.AddDate(0, 0, -int((day.Weekday()+6)%7))
+}
@@ -0,0 +1,188 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func formatDays(days []time.Time) []string {
+	res := make([]string, len(days))
+	for i, d := range days {
+		res[i] = d.Format("2006-01-02")
+	}
+	return res
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"rrule:freq=weekly;interval=2;byday=mo,th", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"FREQ=WEEKLY;INTERVAL=1", "FREQ=WEEKLY"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{"FREQ=YEARLY;UNTIL=20301231T235959Z", "FREQ=YEARLY;UNTIL=20301231"},
+	} {
+		r, err := Parse(tc.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.in, err)
+			continue
+		}
+		if got := r.String(); got != tc.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"WEEKLY",
+		"FREQ=",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=x",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;COUNT=3",
+	} {
+		if r, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, r)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	// 2024-01-01 is a Monday
+	monday := date(2024, 1, 1)
+	for _, tc := range []struct {
+		rule         string
+		start, after time.Time
+		until        time.Time
+		want         []string
+	}{
+		{"FREQ=DAILY;INTERVAL=3", monday, monday.AddDate(0, 0, -1), date(2024, 1, 10),
+			[]string{"2024-01-01", "2024-01-04", "2024-01-07", "2024-01-10"}},
+		{"FREQ=DAILY", monday, date(2024, 1, 3), date(2024, 1, 5),
+			[]string{"2024-01-04", "2024-01-05"}},
+		{"FREQ=DAILY;BYDAY=SA,SU", monday, monday, date(2024, 1, 10),
+			[]string{"2024-01-06", "2024-01-07"}},
+		{"FREQ=DAILY;UNTIL=20240103", monday, monday.AddDate(0, 0, -1), date(2024, 1, 31),
+			[]string{"2024-01-01", "2024-01-02", "2024-01-03"}},
+		{"FREQ=WEEKLY;INTERVAL=2", monday, monday.AddDate(0, 0, -1), date(2024, 1, 31),
+			[]string{"2024-01-01", "2024-01-15", "2024-01-29"}},
+		// weeks are counted from the Monday of the start, not from the start
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,FR", date(2024, 1, 3), monday, date(2024, 1, 20),
+			[]string{"2024-01-05", "2024-01-16", "2024-01-19"}},
+		{"FREQ=MONTHLY", date(2024, 1, 31), monday, date(2024, 5, 31),
+			[]string{"2024-01-31", "2024-03-31", "2024-05-31"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", monday, monday, date(2024, 4, 30),
+			[]string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"}},
+		{"FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=1,15", monday, monday.AddDate(0, 0, -1), date(2024, 4, 30),
+			[]string{"2024-01-01", "2024-01-15", "2024-03-01", "2024-03-15"}},
+		{"FREQ=YEARLY", date(2024, 2, 29), monday, date(2032, 12, 31),
+			[]string{"2024-02-29", "2028-02-29", "2032-02-29"}},
+	} {
+		r, err := Parse(tc.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.rule, err)
+		}
+		got := formatDays(r.Between(tc.start, tc.after, tc.until))
+		if len(got) != len(tc.want) {
+			t.Errorf("%s from %s: got %q, want %q", tc.rule, tc.start.Format("2006-01-02"), got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s from %s: got %q, want %q", tc.rule, tc.start.Format("2006-01-02"), got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestBetweenTimeZone(t *testing.T) {
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	r, err := Parse("FREQ=DAILY;UNTIL=20240102")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-01-01 late in UTC is already Jan 2 in Tokyo
+	start := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC).In(tokyo)
+	days := r.Between(start, start.AddDate(0, 0, -1), start.AddDate(0, 0, 7))
+	if len(days) != 1 {
+		t.Fatalf("got %q, want only 2024-01-02, the UNTIL date", formatDays(days))
+	}
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, tokyo); !days[0].Equal(want) || days[0].Location() != tokyo {
+		t.Errorf("got %s, want midnight in Tokyo %s", days[0], want)
+	}
+}
+
+// Next gives the first day of Between, without going through every day
+func TestNextAsBetween(t *testing.T) {
+	start := date(2023, 1, 31)
+	for _, rule := range []string{
+		"FREQ=DAILY", "FREQ=DAILY;INTERVAL=3;BYDAY=MO,FR", "FREQ=WEEKLY", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+		"FREQ=MONTHLY", "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1,15", "FREQ=MONTHLY;INTERVAL=5", "FREQ=YEARLY",
+		"FREQ=YEARLY;INTERVAL=2", "FREQ=MONTHLY;UNTIL=20230520",
+	} {
+		r, err := Parse(rule)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", rule, err)
+		}
+		end := start.AddDate(1, 2, 0)
+		all := r.Between(start, start.AddDate(0, 0, -4), end.AddDate(4*r.Interval+1, 0, 0))
+		for after := start.AddDate(0, 0, -3); after.Before(end); after = after.AddDate(0, 0, 1) {
+			var want time.Time
+			for _, day := range all {
+				if day.After(after) && !day.After(after.AddDate(4*r.Interval+1, 0, 0)) {
+					want = day
+					break
+				}
+			}
+			if got, ok := r.Next(start, after); !got.Equal(want) || ok != !want.IsZero() {
+				t.Fatalf("%s: Next(after %s) = %s, %v, want %s", rule, after.Format("2006-01-02"), got, ok, want)
+			}
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	r, err := Parse("FREQ=YEARLY;INTERVAL=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Feb 29 every third year is every 12 years
+	next, ok := r.Next(date(2024, 2, 29), date(2024, 2, 29))
+	if !ok || !next.Equal(date(2036, 2, 29)) {
+		t.Errorf("Next = %s, %v, want 2036-02-29", next, ok)
+	}
+
+	// large intervals jump to the years the rule may recur in
+	r, err = Parse("FREQ=YEARLY;INTERVAL=500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, ok = r.Next(date(2024, 5, 10), date(2024, 5, 10)); !ok || !next.Equal(date(2524, 5, 10)) {
+		t.Errorf("Next = %s, %v, want 2524-05-10", next, ok)
+	}
+
+	r, err = Parse("FREQ=WEEKLY;UNTIL=20240110")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, ok = r.Next(date(2024, 1, 1), date(2024, 1, 8)); ok {
+		t.Errorf("Next past UNTIL = %s, want none", next)
+	}
+}
@@ -0,0 +1,247 @@
+// Package table renders rows in aligned columns for terminals, with colors and truncation to the terminal width
+package table
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Style is the ANSI SGR code of a cell, like "31" for red
+type Style string
+
+const (
+	StyleNone   Style = ""
+	StyleBold   Style = "1"
+	StyleDim    Style = "2"
+	StyleRed    Style = "31"
+	StyleGreen  Style = "32"
+	StyleYellow Style = "33"
+	StyleCyan   Style = "36"
+)
+
+type Cell struct {
+	Text  string
+	Style Style
+}
+
+// Column describes the layout of a column
+type Column struct {
+	// Width is the width of the column, cells longer than it overflow unless Truncate is set.
+	// Zero fits the widest cell, which holds the rows until Flush
+	Width int
+	// Right aligns the cells to the right, like for numbers
+	Right bool
+	// Truncate lets the column shrink to keep the rows within MaxWidth, cutting cells with an ellipsis
+	Truncate bool
+}
+
+// minTruncated is the width columns aren't truncated below, so they stay recognizable
+const minTruncated = 8
+
+const separator = "  "
+
+// Table writes rows in aligned columns. Rows are written as they're added when all columns but the last have Width,
+// so long lists needn't wait on being fully loaded; otherwise they're written on Flush
+type Table struct {
+	W       io.Writer
+	Columns []Column
+	// MaxWidth is the width to keep the rows within, usually the terminal's. Zero for no limit
+	MaxWidth int
+	// Color enables the styles of the cells
+	Color bool
+	// Indent is the number of spaces in front of each row
+	Indent int
+
+	rows [][]Cell
+}
+
+// Row adds a row, of a cell per column
+func (t *Table) Row(cells ...Cell) error {
+	if t.streaming() {
+		return t.write([][]Cell{cells})
+	}
+	t.rows = append(t.rows, cells)
+	return nil
+}
+
+// Flush writes the rows held
+func (t *Table) Flush() error {
+	rows := t.rows
+	t.rows = nil
+	return t.write(rows)
+}
+
+func (t *Table) streaming() bool {
+	for _, c := range t.Columns[:len(t.Columns)-1] {
+		if c.Width == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Table) write(rows [][]Cell) error {
+	widths := t.widths(rows)
+	last := len(t.Columns) - 1
+
+	var b, line strings.Builder
+	for _, row := range rows {
+		line.Reset()
+		line.WriteString(strings.Repeat(" ", t.Indent))
+		for i, c := range t.Columns {
+			var cell Cell
+			if i < len(row) {
+				cell = row[i]
+			}
+
+			// line breaks & tabs would break the alignment
+			text := strings.Map(flatten, cell.Text)
+			if c.Truncate || (i == last && t.MaxWidth > 0) {
+				text = truncate(text, widths[i])
+			}
+			pad := strings.Repeat(" ", maxInt(widths[i]-Width(text), 0))
+			if t.Color && cell.Style != StyleNone && text != "" {
+				text = fmt.Sprintf("\x1b[%sm%s\x1b[0m", cell.Style, text)
+			}
+
+			switch {
+			case c.Right:
+				line.WriteString(pad + text)
+			default:
+				line.WriteString(text + pad)
+			}
+			if i < last {
+				line.WriteString(separator)
+			}
+		}
+		// no trailing spaces, as of empty cells at the end
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(t.W, b.String())
+	return err
+}
+
+// widths gives the width of each column for the rows, shrinking the truncatable ones to fit MaxWidth
+func (t *Table) widths(rows [][]Cell) []int {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = c.Width
+		if c.Width > 0 {
+			continue
+		}
+		for _, row := range rows {
+			if i < len(row) {
+				widths[i] = maxInt(widths[i], Width(strings.Map(flatten, row[i].Text)))
+			}
+		}
+	}
+
+	if t.MaxWidth == 0 {
+		return widths
+	}
+	last := len(widths) - 1
+	if t.streaming() {
+		// the rows to come are unknown, so the last column takes whatever is left
+		widths[last] = t.MaxWidth
+	}
+
+	total := t.Indent + len(separator)*last
+	for _, w := range widths {
+		total += w
+	}
+	// shrink from the last column, which usually holds the longest text
+	for i := last; i >= 0 && total > t.MaxWidth; i-- {
+		if !t.Columns[i].Truncate && i != last {
+			continue
+		}
+		shrunk := maxInt(widths[i]-(total-t.MaxWidth), minInt(widths[i], minTruncated))
+		total -= widths[i] - shrunk
+		widths[i] = shrunk
+	}
+	return widths
+}
+
+func flatten(r rune) rune {
+	if r == '\n' || r == '\r' || r == '\t' {
+		return ' '
+	}
+	return r
+}
+
+// truncate cuts the text to the width, ending with an ellipsis when cut
+func truncate(text string, width int) string {
+	if Width(text) <= width {
+		return text
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	w := 0
+	for _, r := range text {
+		if w+runeWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		w += runeWidth(r)
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+// Width is the number of terminal cells the text takes
+func Width(text string) int {
+	if isASCII(text) {
+		return len(text)
+	}
+	w := 0
+	for _, r := range text {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// runeWidth approximates the East Asian width: wide characters, like CJK & emoji, take 2 cells
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF, // CJK ... Yi
+		r >= 0xAC00 && r <= 0xD7A3, // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF, // CJK compatibility ideographs
+		r >= 0xFE30 && r <= 0xFE4F, // CJK compatibility forms
+		r >= 0xFF00 && r <= 0xFF60, // fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1FAFF, // emoji
+		r >= 0x20000 && r <= 0x3FFFD: // CJK extensions
+		return 2
+	}
+	return 1
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
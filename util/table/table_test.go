@@ -0,0 +1,130 @@
+package table
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWidth(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"café", 4},
+		{"日本語", 6},
+		{"ok 👍", 5},
+	} {
+		if got := Width(tc.text); got != tc.want {
+			t.Errorf("Width(%q) = %d, want %d", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exact", 5, "exact"},
+		{"too long", 5, "too …"},
+		{"abc", 1, "…"},
+		{"abc", 0, ""},
+		// wide characters aren't split, leaving a cell short
+		{"日本語テキスト", 6, "日本…"},
+		{"日本語テキスト", 5, "日本…"},
+		{"日本語", 6, "日本語"},
+	} {
+		got := truncate(tc.text, tc.width)
+		if got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.text, tc.width, got, tc.want)
+		}
+		if Width(got) > tc.width {
+			t.Errorf("truncate(%q, %d) = %q, wider than %d", tc.text, tc.width, got, tc.width)
+		}
+	}
+}
+
+func TestWidths(t *testing.T) {
+	rows := [][]Cell{
+		{{Text: "1"}, {Text: "short"}, {Text: "x"}},
+		{{Text: "12"}, {Text: "a much longer name"}, {Text: "note"}},
+	}
+	for _, tc := range []struct {
+		name     string
+		columns  []Column
+		maxWidth int
+		want     []int
+	}{
+		{"fit the widest cells", []Column{{}, {Truncate: true}, {}}, 0, []int{2, 18, 4}},
+		{"fixed widths kept", []Column{{Width: 5}, {Truncate: true}, {}}, 0, []int{5, 18, 4}},
+		{"shrink the truncatable", []Column{{}, {Truncate: true}, {}}, 20, []int{2, 10, 4}},
+		{"the last under the minimum kept", []Column{{}, {Truncate: true}, {}}, 27, []int{2, 17, 4}},
+		{"not below the minimum", []Column{{}, {Truncate: true}, {}}, 10, []int{2, 8, 4}},
+		{"others never shrink", []Column{{}, {}, {}}, 20, []int{2, 18, 4}},
+		{"streaming, the last takes what's left", []Column{{Width: 2}, {Width: 5}, {}}, 30, []int{2, 5, 19}},
+	} {
+		table := &Table{Columns: tc.columns, MaxWidth: tc.maxWidth}
+		got := table.widths(rows)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: widths = %v, want %v", tc.name, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: widths = %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	var b strings.Builder
+	table := &Table{
+		W:        &b,
+		Columns:  []Column{{Right: true}, {Truncate: true}, {}},
+		MaxWidth: 20,
+		Indent:   1,
+	}
+	table.Row(Cell{Text: "1"}, Cell{Text: "short"})
+	table.Row(Cell{Text: "12"}, Cell{Text: "a much\nlonger name"}, Cell{Text: "note"})
+	if b.Len() > 0 {
+		t.Fatalf("rows written before Flush: %q", b.String())
+	}
+	if err := table.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "  1  short\n" +
+		" 12  a much l…  note\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestTableStreaming(t *testing.T) {
+	var b strings.Builder
+	table := &Table{W: &b, Columns: []Column{{Width: 3}, {}}, MaxWidth: 12}
+
+	table.Row(Cell{Text: "1"}, Cell{Text: "a long line to cut"})
+	if want := "1    a long …\n"; b.String() != want {
+		t.Errorf("got %q, want %q written on Row", b.String(), want)
+	}
+}
+
+func TestTableColor(t *testing.T) {
+	var b strings.Builder
+	table := &Table{W: &b, Columns: []Column{{Width: 4}, {}}, Color: true}
+
+	table.Row(Cell{Text: "x", Style: StyleRed}, Cell{Text: "", Style: StyleBold})
+	table.Row(Cell{Text: "y"}, Cell{Text: "z", Style: StyleDim})
+	// padding stays out of the styles, & empty cells have none
+	want := "\x1b[31mx\x1b[0m\n" +
+		"y     \x1b[2mz\x1b[0m\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}